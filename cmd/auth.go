@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	loginWithToken bool
+	authCmd        = &cobra.Command{
+		Use:   "auth",
+		Short: "manage the GitHub token used by git-matsuri",
+		// authentication is handled by the subcommands themselves
+		PersistentPreRunE: applyGlobalFlags,
+	}
+	authLoginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "save a GitHub token for git-matsuri",
+		Long:  "Save a GitHub token in a file readable only by the current user. The token is prompted for, or read from the standard input with '--with-token'",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthLogin,
+	}
+	authStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "show which GitHub token git-matsuri uses",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthStatus,
+	}
+	authLogoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "remove the GitHub token saved by 'auth login'",
+		Args:  cobra.NoArgs,
+		RunE:  runAuthLogout,
+	}
+)
+
+func readToken(cmd *cobra.Command) (token string, err error) {
+	stdin := int(os.Stdin.Fd()) // #nosec
+	if !loginWithToken && term.IsTerminal(stdin) {
+		cmd.Print("Paste a token created at https://github.com/settings/tokens/new with 'repo', 'user:email' and 'project' permissions: ")
+		var raw []byte
+		raw, err = term.ReadPassword(stdin)
+		cmd.Println()
+		token = strings.TrimSpace(string(raw))
+	} else {
+		token, err = bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		token = strings.TrimSpace(token)
+		if token != "" {
+			err = nil
+		}
+	}
+	if err == nil && token == "" {
+		err = errors.New("no token was provided")
+	}
+	return
+}
+
+func runAuthLogin(cmd *cobra.Command, args []string) (err error) {
+	token, err := readToken(cmd)
+	if err != nil {
+		return
+	}
+	backend, err := matsuri.NewTokenBackend(token)
+	if err != nil {
+		return
+	}
+	matsuri.SetBackend(backend)
+	login, err := matsuri.GetAuthenticatedUser()
+	if err != nil {
+		return matsuri.DescribeError(err)
+	}
+	path, err := matsuri.SaveToken(token)
+	if err != nil {
+		return
+	}
+	cmd.Printf("Logged in as %s, the token was saved to %s\n", login, path)
+	if os.Getenv(matsuri.TokenName) != "" {
+		cmd.Printf("WARN: %s is set and takes precedence over the saved token\n", matsuri.TokenName)
+	}
+	return
+}
+
+func runAuthStatus(cmd *cobra.Command, args []string) (err error) {
+	_, source, err := matsuri.FindToken()
+	if err != nil {
+		return
+	}
+	if !matsuri.HasBackend() {
+		backend, backendErr := matsuri.NewDefaultBackend()
+		if backendErr != nil {
+			return backendErr
+		}
+		matsuri.SetBackend(backend)
+	}
+	login, err := matsuri.GetAuthenticatedUser()
+	if err != nil {
+		cmd.Printf("A token was found in %s but it could not be used\n", source)
+		return matsuri.DescribeError(err)
+	}
+	cmd.Printf("Logged in as %s using %s\n", login, source)
+	return
+}
+
+func runAuthLogout(cmd *cobra.Command, args []string) (err error) {
+	removed, err := matsuri.RemoveToken()
+	if err != nil {
+		return
+	}
+	if removed {
+		cmd.Println("The saved token was removed")
+	} else {
+		cmd.Println("There was no saved token")
+	}
+	if _, source, findErr := matsuri.FindToken(); findErr == nil {
+		cmd.Printf("WARN: a token is still available from %s\n", source)
+	}
+	return
+}
+
+func init() {
+	authLoginCmd.Flags().BoolVar(&loginWithToken, "with-token", false, "read the token from the standard input")
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd)
+	rootCmd.AddCommand(authCmd)
+}
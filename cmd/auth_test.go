@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+)
+
+// withoutToken hides the tokens of the environment, and makes git-matsuri forget the token it found in a previous test.
+func withoutToken(t *testing.T) {
+	t.Helper()
+	t.Setenv(matsuri.TokenName, "")
+	t.Setenv("GH_CONFIG_DIR", "")
+	// the token is kept by the process until the token file is removed
+	forget := func() {
+		if _, err := matsuri.RemoveToken(); err != nil {
+			t.Error(err)
+		}
+	}
+	forget()
+	t.Cleanup(forget)
+}
+
+func TestAuthStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		// setUp puts a token in the source under test
+		setUp      func(t *testing.T, r *testRepo)
+		wantSource string
+	}{
+		{
+			name:       "environment",
+			setUp:      func(t *testing.T, r *testRepo) { t.Setenv(matsuri.TokenName, "env-token") },
+			wantSource: "the MATSURI_TOKEN environment variable",
+		},
+		{
+			name: "token file",
+			setUp: func(t *testing.T, r *testRepo) {
+				if _, err := matsuri.SaveToken("file-token"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantSource: "the token file {home}/git-matsuri/token",
+		},
+		{
+			name: "git credential",
+			setUp: func(t *testing.T, r *testRepo) {
+				r.git(r.dir, "config", "--global", "credential.helper", "!f() { echo username=volunteer; echo password=git-token; }; f")
+			},
+			wantSource: "git credential for github.com",
+		},
+		{
+			name: "GitHub CLI",
+			setUp: func(t *testing.T, r *testRepo) {
+				dir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "gh")
+				if err := os.MkdirAll(dir, 0o700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte("github.com:\n    oauth_token: gh-token\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantSource: "the GitHub CLI configuration {home}/gh/hosts.yml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRepo(t)
+			withoutToken(t)
+			tt.setUp(t, r)
+
+			out := r.mustRun("auth", "status")
+			want := "Logged in as volunteer using " + strings.ReplaceAll(tt.wantSource, "{home}", os.Getenv("XDG_CONFIG_HOME")) + "\n"
+			if out != want {
+				t.Errorf("auth status printed %q, want %q", out, want)
+			}
+		})
+	}
+}
+
+func TestAuthStatusWithoutToken(t *testing.T) {
+	r := newTestRepo(t)
+	withoutToken(t)
+	if _, err := r.run("auth", "status"); err == nil || !strings.Contains(err.Error(), "no GitHub token found") {
+		t.Errorf("auth status error = %v", err)
+	}
+}
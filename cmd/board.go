@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/terminal"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+const boardHelp = "←↑↓→ select  < > move card  o open  s start  r refresh  q quit"
+
+var (
+	boardRefresh time.Duration
+	boardCmd     = &cobra.Command{
+		Use:   "board",
+		Short: "browse and update the kanban in an interactive board",
+		Long: "Show the project columns side by side in the terminal. Select a card with the arrow keys, " +
+			"move it to the previous or next column with < and >, open it in the browser with o, " +
+			"or start working on it with s. The board refreshes in the background.",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{permissionsAnnotation: "repo,project:write"},
+		RunE:        runBoard,
+	}
+)
+
+// boardLoad is the result of loading the board in the background.
+type boardLoad struct {
+	project *matsuri.Project
+	report  *matsuri.KanbanReport
+	err     error
+}
+
+func loadBoard() boardLoad {
+	project, err := matsuri.GetProject()
+	if err != nil {
+		return boardLoad{err: err}
+	}
+	// a partial report still shows the issues that could be fetched
+	report, err := matsuri.GetKanbanReport(project, 0)
+	return boardLoad{project: project, report: report, err: err}
+}
+
+// boardMove is a card move sent to GitHub in the background.
+type boardMove struct {
+	issue    *matsuri.ReportIssue
+	from, to string
+	err      error
+}
+
+// boardState is what the interactive board shows.
+type boardState struct {
+	project *matsuri.Project
+	report  *matsuri.KanbanReport
+	// column and row locate the selected card.
+	column, row int
+	status      string
+	refreshing  bool
+	// stale is set when the board changed while it was being refreshed, so that it is refreshed again.
+	stale       bool
+	refreshedAt time.Time
+}
+
+func (s *boardState) selected() *matsuri.ReportIssue {
+	if s.report == nil || s.column >= len(s.report.Columns) {
+		return nil
+	}
+	issues := s.report.Columns[s.column].Issues
+	if s.row >= len(issues) {
+		return nil
+	}
+	return issues[s.row]
+}
+
+// clamp keeps the selection within the board.
+func (s *boardState) clamp() {
+	if s.report == nil || len(s.report.Columns) == 0 {
+		s.column, s.row = 0, 0
+		return
+	}
+	if s.column >= len(s.report.Columns) {
+		s.column = len(s.report.Columns) - 1
+	}
+	if s.column < 0 {
+		s.column = 0
+	}
+	if size := len(s.report.Columns[s.column].Issues); s.row >= size {
+		s.row = size - 1
+	}
+	if s.row < 0 {
+		s.row = 0
+	}
+}
+
+// apply shows a freshly loaded board, keeping the same card selected if it is still there.
+func (s *boardState) apply(load boardLoad) {
+	s.refreshing = false
+	if load.err != nil {
+		s.status = "Refresh failed: " + matsuri.DescribeError(load.err).Error()
+	}
+	if load.report == nil {
+		return
+	}
+	previous := s.selected()
+	s.project, s.report, s.refreshedAt = load.project, load.report, time.Now()
+	if previous != nil {
+		for i, column := range s.report.Columns {
+			for j, issue := range column.Issues {
+				if issue.Repository == previous.Repository && issue.Number == previous.Number {
+					s.column, s.row = i, j
+				}
+			}
+		}
+	}
+	s.clamp()
+}
+
+// moveSelected moves the selected card to the adjacent column on screen, before GitHub is updated.
+func (s *boardState) moveSelected(delta int) (move *boardMove) {
+	issue := s.selected()
+	target := s.column + delta
+	if issue == nil || target < 0 || target >= len(s.report.Columns) {
+		return nil
+	}
+	from, to := s.report.Columns[s.column], s.report.Columns[target]
+	from.Issues = append(from.Issues[:s.row:s.row], from.Issues[s.row+1:]...)
+	to.Issues = append([]*matsuri.ReportIssue{issue}, to.Issues...)
+	issue.Column = to.Name
+	s.column, s.row = target, 0
+	return &boardMove{issue: issue, from: from.Name, to: to.Name}
+}
+
+// render draws the columns side by side, scrolling horizontally when they do not all fit.
+func (s *boardState) render(t *terminal.Terminal) {
+	width, height := t.Size()
+	var b strings.Builder
+
+	header := "Loading..."
+	if s.report != nil {
+		header = s.report.Project
+	}
+	info := ""
+	if s.refreshing {
+		info = "refreshing..."
+	} else if !s.refreshedAt.IsZero() {
+		info = "updated at " + s.refreshedAt.Format("15:04:05")
+	}
+	fmt.Fprintf(&b, "\x1b[1m%s\x1b[0m%s\x1b[2m%s\x1b[0m\r\n",
+		header, strings.Repeat(" ", maxInt(1, width-len([]rune(header))-len(info))), info)
+
+	if s.report != nil && len(s.report.Columns) != 0 {
+		const minColumnWidth = 24
+		shown := len(s.report.Columns)
+		if shown*minColumnWidth > width {
+			shown = maxInt(1, width/minColumnWidth)
+		}
+		first := 0
+		if s.column >= shown {
+			first = s.column - shown + 1
+		}
+		columnWidth := width / shown
+		// each card takes three lines, the last one blank
+		bodyHeight := height - 3
+		cardsShown := maxInt(1, (bodyHeight-1)/3)
+		offset := 0
+		if s.row >= cardsShown {
+			offset = s.row - cardsShown + 1
+		}
+
+		lines := make([]strings.Builder, bodyHeight)
+		for i := first; i < first+shown && i < len(s.report.Columns); i++ {
+			column := s.report.Columns[i]
+			cellWidth := columnWidth - 1
+			title := fmt.Sprintf("%s (%d)", column.Name, len(column.Issues))
+			if i == s.column {
+				lines[0].WriteString("\x1b[1;4m" + terminal.Pad(title, cellWidth) + "\x1b[0m ")
+			} else {
+				lines[0].WriteString("\x1b[1m" + terminal.Pad(title, cellWidth) + "\x1b[0m ")
+			}
+			start := 0
+			if i == s.column {
+				start = offset
+			}
+			for line := 1; line < bodyHeight; line++ {
+				j, part := start+(line-1)/3, (line-1)%3
+				text := ""
+				if j < len(column.Issues) {
+					issue := column.Issues[j]
+					switch part {
+					case 0:
+						text = fmt.Sprintf("#%d %s", issue.Number, issue.Repository)
+						if len(issue.Assignees) != 0 {
+							text += " @" + strings.Join(issue.Assignees, " @")
+						}
+					case 1:
+						text = issue.Title
+					}
+					if part != 2 && i == s.column && j == s.row {
+						lines[line].WriteString("\x1b[7m" + terminal.Pad(text, cellWidth) + "\x1b[0m ")
+						continue
+					}
+				}
+				lines[line].WriteString(terminal.Pad(text, cellWidth) + " ")
+			}
+		}
+		for i := range lines {
+			b.WriteString(lines[i].String() + "\r\n")
+		}
+	} else {
+		for i := 0; i < height-3; i++ {
+			b.WriteString("\r\n")
+		}
+	}
+
+	fmt.Fprintf(&b, "%s\r\n\x1b[2m%s\x1b[0m", terminal.Truncate(s.status, width), terminal.Truncate(boardHelp, width))
+	t.Draw(b.String())
+}
+
+// maxInt is the builtin max, which needs Go 1.21.
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// openInBrowser opens a URL with the default browser of the system.
+func openInBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
+
+// runBoardLoop runs the board until the user quits, returning the issue to start working on, if any.
+func runBoardLoop(t *terminal.Terminal) (start *matsuri.ReportIssue) {
+	// done stops the goroutines once the loop returns, so that none is left blocked or reading the input of what comes next
+	done := make(chan struct{})
+	defer close(done)
+	keys := make(chan []string)
+	go func() {
+		for {
+			if err := t.WaitInput(done); errors.Is(err, terminal.ErrStopped) {
+				return
+			} else if err != nil {
+				close(keys)
+				return
+			}
+			k, err := t.ReadKeys()
+			if err != nil {
+				close(keys)
+				return
+			}
+			select {
+			case keys <- k:
+			case <-done:
+				return
+			}
+		}
+	}()
+	loads := make(chan boardLoad)
+	moves := make(chan *boardMove)
+	ticker := time.NewTicker(boardRefresh)
+	defer ticker.Stop()
+
+	s := &boardState{}
+	refresh := func() {
+		if s.refreshing {
+			s.stale = true
+			return
+		}
+		s.refreshing, s.stale = true, false
+		go func() {
+			load := loadBoard()
+			select {
+			case loads <- load:
+			case <-done:
+			}
+		}()
+	}
+	refresh()
+	currentRepo, _ := matsuri.GetRepoName()
+	for {
+		s.render(t)
+		select {
+		case load := <-loads:
+			s.apply(load)
+			if s.stale {
+				refresh()
+			}
+		case <-ticker.C:
+			refresh()
+		case move := <-moves:
+			if move.err != nil {
+				s.status = fmt.Sprintf("Could not move #%d: %s", move.issue.Number, matsuri.DescribeError(move.err).Error())
+			} else {
+				s.status = fmt.Sprintf("Moved #%d from %s to %s", move.issue.Number, move.from, move.to)
+			}
+			refresh()
+		case pressed, ok := <-keys:
+			if !ok {
+				return
+			}
+			for _, key := range pressed {
+				switch key {
+				case "q", terminal.KeyEscape, terminal.KeyCtrlC:
+					return
+				case terminal.KeyLeft, "h":
+					s.column--
+					s.clamp()
+				case terminal.KeyRight, "l":
+					s.column++
+					s.clamp()
+				case terminal.KeyUp, "k":
+					s.row--
+					s.clamp()
+				case terminal.KeyDown, "j":
+					s.row++
+					s.clamp()
+				case "<", ">":
+					delta := 1
+					if key == "<" {
+						delta = -1
+					}
+					if move := s.moveSelected(delta); move != nil {
+						s.status = fmt.Sprintf("Moving #%d to %s...", move.issue.Number, move.to)
+						// the board being refreshed may not show the move yet
+						s.stale = s.refreshing
+						project := s.project
+						go func() {
+							move.err = matsuri.MoveIssueCard(project, move.issue.Repository, move.issue.Number, move.from, move.to)
+							select {
+							case moves <- move:
+							case <-done:
+							}
+						}()
+					}
+				case "o":
+					if issue := s.selected(); issue != nil {
+						if err := openInBrowser(issue.URL); err != nil {
+							s.status = "Could not open the browser: " + err.Error()
+						}
+					}
+				case "s":
+					if issue := s.selected(); issue != nil {
+						if issue.PullRequest {
+							s.status = fmt.Sprintf("#%d is a pull request, only issues can be started", issue.Number)
+							continue
+						}
+						if issue.Repository != currentRepo {
+							s.status = fmt.Sprintf("#%d belongs to %s, run start from a clone of it", issue.Number, issue.Repository)
+							continue
+						}
+						return issue
+					}
+				case "r":
+					refresh()
+				}
+			}
+		}
+	}
+}
+
+func runBoard(cmd *cobra.Command, args []string) (err error) {
+	if boardRefresh <= 0 {
+		return errors.New("--refresh must be positive")
+	}
+	if !terminal.IsTerminal(cmd.InOrStdin(), cmd.OutOrStdout()) {
+		return errors.New("the board needs a terminal, use `git matsuri kanban` instead")
+	}
+	t, err := terminal.Open(cmd.InOrStdin().(*os.File), cmd.OutOrStdout().(*os.File))
+	if err != nil {
+		return
+	}
+	issue := runBoardLoop(t)
+	if err = t.Close(); err != nil || issue == nil {
+		return
+	}
+	return runStart(cmd, []string{strconv.Itoa(issue.Number)})
+}
+
+func init() {
+	boardCmd.Flags().DurationVar(&boardRefresh, "refresh", time.Minute, "how often the board is refreshed from GitHub")
+	rootCmd.AddCommand(boardCmd)
+}
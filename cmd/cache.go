@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "manage the cache of GitHub API responses",
+		// the cache can be managed without a token or a repository
+		PersistentPreRunE: applyGlobalFlags,
+	}
+	cacheClearCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "remove all cached GitHub API responses",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheClear,
+	}
+)
+
+func runCacheClear(cmd *cobra.Command, args []string) (err error) {
+	dir, err := matsuri.CacheDir()
+	if err != nil {
+		return
+	}
+	if err = matsuri.ClearCache(); err != nil {
+		return
+	}
+	cmd.Printf("Cleared %s\n", dir)
+	return
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+)
+
+func TestCacheClear(t *testing.T) {
+	r := newTestRepo(t)
+	dir, err := matsuri.CacheDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "response"), []byte("HTTP/1.1 200 OK\r\n\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := r.mustRun("cache", "clear")
+	if !strings.Contains(out, "Cleared "+dir) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "response")); !os.IsNotExist(err) {
+		t.Errorf("the cached response is still there: %v", err)
+	}
+	// clearing an empty cache is fine
+	r.mustRun("cache", "clear")
+}
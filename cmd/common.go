@@ -1,10 +1,100 @@
 package cmd
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/internal/picker"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
 )
 
+// permissionsAnnotation is the annotation listing, separated by commas, the permissions a command needs from the GitHub token.
+const permissionsAnnotation = "permissions"
+
+func requiredPermissions(cmd *cobra.Command) (permissions []matsuri.Permission) {
+	value := cmd.Annotations[permissionsAnnotation]
+	if value == "" {
+		return
+	}
+	for _, p := range strings.Split(value, ",") {
+		permissions = append(permissions, matsuri.Permission(p))
+	}
+	return
+}
+
+// yearArg selects the project year from the optional positional argument of kanban and todo.
+func yearArg(cmd *cobra.Command, args []string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+	year, err := strconv.Atoi(args[0])
+	if err != nil || year < 1000 {
+		return fmt.Errorf("invalid year %q", args[0])
+	}
+	if cmd.Flags().Changed("year") && matsuri.Year != year {
+		return fmt.Errorf("the year %d conflicts with --year %d", year, matsuri.Year)
+	}
+	matsuri.Year = year
+	return
+}
+
+// pickIssue asks the user to choose one of the issues returned by issueGetter.
+func pickIssue(cmd *cobra.Command, issueGetter matsuri.IssueGetterFunc) (issueNumber int, err error) {
+	issues, err := issueGetter()
+	if err != nil {
+		return
+	}
+	items := make([]picker.Item, len(issues))
+	for i, issue := range issues {
+		label := fmt.Sprintf("#%d %s", issue.GetNumber(), issue.GetTitle())
+		for _, l := range issue.Labels {
+			label += " [" + l.GetName() + "]"
+		}
+		items[i] = picker.Item{Label: label, Preview: issue.GetBody()}
+	}
+	index, err := picker.Pick(cmd.InOrStdin(), cmd.OutOrStdout(), "Issue", items)
+	if err != nil {
+		return
+	}
+	issueNumber = issues[index].GetNumber()
+	return
+}
+
+// resolveIssue gets the issue to work on from the optional argument, or from the current branch when omitted,
+// along with the branch holding the work: the current branch if it belongs to the issue, its topic branch otherwise.
+// When neither tells the issue, the user picks one of the issues in progress.
+func resolveIssue(cmd *cobra.Command, args []string) (issueNumber int, branch string, err error) {
+	current, err := git.CurrentBranch("")
+	if err != nil {
+		return
+	}
+	currentIssue, onIssueBranch := matsuri.IssueNumberFromBranch(current)
+	if len(args) == 0 {
+		if onIssueBranch {
+			return currentIssue, current, nil
+		}
+		patterns := strings.Join(matsuri.BranchPatterns(), ", ")
+		cmd.Printf("The current branch %q is not named like an issue branch (%s)\n", current, patterns)
+		if issueNumber, err = pickIssue(cmd, matsuri.GetInProgressIssues); err != nil {
+			err = fmt.Errorf("no issue was chosen (%s), pass its number or use a branch named like: %s", err.Error(), patterns)
+			return
+		}
+		branch = matsuri.BranchName(issueNumber)
+		return
+	}
+	if issueNumber, err = strconv.Atoi(args[0]); err != nil {
+		return
+	}
+	branch = matsuri.BranchName(issueNumber)
+	if onIssueBranch && currentIssue == issueNumber {
+		branch = current
+	}
+	return
+}
+
 func completeIssues(_ *cobra.Command, args []string, toComplete string, issueGetter matsuri.IssueGetterFunc) ([]string, cobra.ShellCompDirective) {
 	if len(args) != 0 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
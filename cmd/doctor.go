@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/google/go-github/v29/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorCmd = &cobra.Command{
+		Use:   "doctor",
+		Short: "check the setup of git-matsuri",
+		Long:  "Check the setup of git-matsuri: git, configuration files, repository, project and the remaining GitHub API quota",
+		Args:  cobra.NoArgs,
+		// the token and configuration are checked by the command itself, and reported rather than failing
+		PersistentPreRunE: applyGlobalFlags,
+		RunE:              runDoctor,
+	}
+)
+
+func printRate(cmd *cobra.Command, name string, rate *github.Rate) {
+	if rate == nil {
+		return
+	}
+	cmd.Printf("%s: %d/%d requests left, resets at %s\n", name, rate.Remaining, rate.Limit, rate.Reset.Local().Format("15:04:05"))
+}
+
+func runDoctor(cmd *cobra.Command, args []string) (err error) {
+	failed := false
+	check := func(name string, checkErr error, value string) {
+		if checkErr != nil {
+			failed = true
+			cmd.Printf("%s: ERROR %s\n", name, matsuri.DescribeError(checkErr))
+			return
+		}
+		cmd.Printf("%s: %s\n", name, value)
+	}
+
+	gitPath, gitErr := exec.LookPath("git")
+	check("git", gitErr, gitPath)
+	for _, path := range matsuri.ConfigPaths() {
+		status := "loaded"
+		if _, statErr := os.Stat(path); statErr != nil {
+			status = "not found"
+		}
+		cmd.Printf("configuration file %s: %s\n", path, status)
+	}
+	if config, configErr := matsuri.LoadConfig(); configErr != nil {
+		check("configuration", configErr, "")
+	} else {
+		_ = matsuri.SetConfig(config)
+	}
+	cmd.Printf("organization: %s\n", matsuri.GetConfig().Owner)
+	apiURL := os.Getenv(matsuri.APIURLName)
+	if apiURL == "" {
+		apiURL = "https://api.github.com/"
+	}
+	cmd.Printf("API: %s\n", apiURL)
+	if dir, cacheErr := matsuri.CacheDir(); cacheErr == nil && matsuri.CacheEnabled {
+		cmd.Printf("cache: %s\n", dir)
+	} else {
+		cmd.Println("cache: disabled")
+	}
+
+	repoName, repoErr := matsuri.GetRepoName()
+	check("repository", repoErr, repoName)
+	if !matsuri.HasBackend() {
+		_, source, tokenErr := matsuri.FindToken()
+		if tokenErr != nil {
+			check("token", fmt.Errorf("%w, save one with `git matsuri auth login` or set %s", tokenErr, matsuri.TokenName), "")
+			return errors.New("some checks failed")
+		}
+		cmd.Printf("token source: %s\n", source)
+		b, backendErr := matsuri.NewDefaultBackend()
+		if backendErr != nil {
+			check("API", fmt.Errorf("invalid %s: %w", matsuri.APIURLName, backendErr), "")
+			return errors.New("some checks failed")
+		}
+		matsuri.SetBackend(b)
+	}
+	project, projectErr := matsuri.GetProject()
+	if projectErr == nil {
+		check("project", nil, project.Name)
+	} else {
+		check("project", projectErr, "")
+	}
+	info, infoErr := matsuri.GetTokenInfo()
+	if infoErr != nil {
+		check("token", infoErr, "")
+	} else if info.FineGrained() {
+		check("token", nil, fmt.Sprintf("fine-grained token of %s", info.Login))
+	} else {
+		check("token", nil, fmt.Sprintf("token of %s with scopes %s", info.Login, strings.Join(info.Scopes, ", ")))
+	}
+	limits, limitsErr := matsuri.GetRateLimits()
+	if limitsErr != nil {
+		check("rate limit", limitsErr, "")
+	} else {
+		printRate(cmd, "rate limit", limits.Core)
+		printRate(cmd, "search rate limit", limits.Search)
+	}
+
+	if failed {
+		err = errors.New("some checks failed")
+	}
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+)
+
+func TestDoctor(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	t.Setenv(matsuri.TokenName, "test-token")
+	r.gh.Scopes = []string{"repo", "project"}
+	out := r.mustRun("doctor")
+	for _, line := range []string{
+		"organization: MatsuriJapon\n",
+		"API: https://api.github.com/\n",
+		"repository: web\n",
+		"project: Matsuri 2024\n",
+		"token: token of volunteer with scopes repo, project\n",
+		"rate limit: 5000/5000 requests left",
+		"search rate limit: 30/30 requests left",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("doctor did not report %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestDoctorWithoutProject(t *testing.T) {
+	r := newTestRepo(t)
+	out, err := r.run("doctor")
+	if err == nil || !strings.Contains(out, "project: ERROR") {
+		t.Errorf("doctor error = %v:\n%s", err, out)
+	}
+	// the other checks still run
+	if !strings.Contains(out, "rate limit: 5000/5000 requests left") {
+		t.Errorf("doctor stopped at the first failed check:\n%s", out)
+	}
+}
+
+func TestDoctorWithInvalidConfig(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.userConfig("project_pattern: \"(\"\n")
+	out, err := r.run("doctor")
+	if err == nil || !strings.Contains(out, "configuration: ERROR") {
+		t.Errorf("doctor error = %v:\n%s", err, out)
+	}
+	if !strings.Contains(out, "project: Matsuri 2024\n") {
+		t.Errorf("doctor did not go on with the default settings:\n%s", out)
+	}
+}
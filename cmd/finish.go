@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+var (
+	finishCmd = &cobra.Command{
+		Use:   "finish [ISSUE_NUMBER]",
+		Short: "clean up once the pull request of an issue is merged",
+		Long: "Check that the pull request of the issue was merged, move its card to the Done column, " +
+			"check out and fast-forward the branch it was merged into, and delete the topic branch locally and on GitHub. " +
+			"Branches holding commits that are not in the merged pull request are kept.",
+		Args:              cobra.MaximumNArgs(1),
+		Annotations:       map[string]string{permissionsAnnotation: "repo,project:write"},
+		RunE:              runFinish,
+		ValidArgsFunction: completeInProgressIssuesForProject,
+	}
+)
+
+// unmergedCommits tells why ref cannot be deleted when it has commits that are not in the merged head, and returns "" otherwise.
+func unmergedCommits(ref, head string, pr int) string {
+	count, err := git.Output("", "rev-list", "--count", ref, "^"+head)
+	if err != nil {
+		return fmt.Sprintf("the commits of #%d could not be fetched to compare with it", pr)
+	}
+	if n, _ := strconv.Atoi(count); n != 0 {
+		return fmt.Sprintf("it has %d commit(s) that are not in #%d", n, pr)
+	}
+	return ""
+}
+
+func runFinish(cmd *cobra.Command, args []string) (err error) {
+	issue, branchName, err := resolveIssue(cmd, args)
+	if err != nil {
+		return
+	}
+	cmd.Println("Looking for the pull request...")
+	pr, err := matsuri.GetMergedPullRequest(branchName)
+	if err != nil {
+		return
+	}
+	base, head := pr.GetBase().GetRef(), pr.GetHead().GetSHA()
+	cmd.Printf("Pull request #%d was merged into %s\n", pr.GetNumber(), base)
+
+	current, err := git.CurrentBranch("")
+	if err != nil {
+		return
+	}
+	if current == branchName {
+		var status *git.Status
+		if status, err = git.GetStatus(""); err != nil {
+			return
+		}
+		if !status.Clean() {
+			err = fmt.Errorf("%w:\n%s\nCommit or stash them before finishing", git.ErrDirtyTree, status)
+			return
+		}
+	}
+
+	var skipped []string
+	skip := func(format string, a ...interface{}) {
+		skipped = append(skipped, fmt.Sprintf(format, a...))
+	}
+
+	done := matsuri.GetConfig().Columns.Done
+	cmd.Printf("Moving the card to %s...\n", done)
+	if moveErr := matsuri.MoveProjectCardToDone(issue); moveErr != nil {
+		skip("moving the card to %s: %s", done, matsuri.DescribeError(moveErr).Error())
+	}
+	// the pull request may not be on the board, so we'll ignore errors here
+	_ = matsuri.MoveProjectCardToDone(pr.GetNumber())
+
+	cmd.Println("Fetching changes...")
+	if _, err = git.RunWithProgress(cmd.ErrOrStderr(), "", "fetch", "--prune", "origin"); err != nil {
+		return
+	}
+	// the merged commits are gone from origin when GitHub deleted the branch, but the pull request keeps them
+	if known, _ := git.HasRef("", head+"^{commit}"); !known {
+		_, _ = git.Run("", "fetch", "origin", fmt.Sprintf("refs/pull/%d/head", pr.GetNumber()))
+	}
+
+	cmd.Printf("Checking out %s...\n", base)
+	if out, checkoutErr := git.Run("", "checkout", base); checkoutErr != nil {
+		skip("checking out %s: %s", base, checkoutErr)
+	} else {
+		cmd.Print(out)
+		if _, mergeErr := git.Run("", "merge", "--ff-only", "origin/"+base); mergeErr != nil {
+			skip("fast-forwarding %s, which has diverged from origin/%s", base, base)
+		}
+	}
+
+	if local, _ := git.HasRef("", "refs/heads/"+branchName); local {
+		if current, _ = git.CurrentBranch(""); current == branchName {
+			skip("deleting the local branch %s, which is still checked out", branchName)
+		} else if reason := unmergedCommits("refs/heads/"+branchName, head, pr.GetNumber()); reason != "" {
+			skip("deleting the local branch %s: %s", branchName, reason)
+		} else {
+			cmd.Printf("Deleting the local branch %s...\n", branchName)
+			if _, deleteErr := git.Run("", "branch", "--delete", "--force", branchName); deleteErr != nil {
+				skip("deleting the local branch %s: %s", branchName, deleteErr)
+			}
+		}
+	}
+
+	if remote, _ := git.HasRef("", "refs/remotes/origin/"+branchName); remote {
+		if reason := unmergedCommits("refs/remotes/origin/"+branchName, head, pr.GetNumber()); reason != "" {
+			skip("deleting the branch %s on GitHub: %s", branchName, reason)
+		} else {
+			cmd.Printf("Deleting the branch %s on GitHub...\n", branchName)
+			if _, deleteErr := git.Run("", "push", "origin", "--delete", branchName); deleteErr != nil {
+				skip("deleting the branch %s on GitHub: %s", branchName, deleteErr)
+			}
+		}
+	}
+
+	if len(skipped) != 0 {
+		cmd.Println("Some steps were skipped:")
+		for _, s := range skipped {
+			cmd.Printf("  - %s\n", s)
+		}
+		return
+	}
+	cmd.Printf("%s is finished\n", matsuri.BranchName(issue))
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(finishCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// mergeTestPR opens the pull request of issue 1 and merges it into master, closing the issue like GitHub does.
+func mergeTestPR(t *testing.T, r *testRepo) (sha string) {
+	t.Helper()
+	r.mustRun("start", "1")
+	sha = r.commit("header.html", "<header>\n")
+	r.mustRun("pr")
+	r.gh.MergePullRequest("web", 2, sha)
+	closeIssue(t, r, 1)
+	r.git(r.dir, "push", "--quiet", "origin", "ISSUE-1:master")
+	return
+}
+
+func TestFinish(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	sha := mergeTestPR(t, r)
+
+	out := r.mustRun("finish")
+	if !strings.Contains(out, "ISSUE-1 is finished") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if status := itemStatus(r, project, 1); status != "Done" {
+		t.Errorf("the issue is in %q", status)
+	}
+	if status := itemStatus(r, project, 2); status != "Done" {
+		t.Errorf("the pull request is in %q", status)
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "master" {
+		t.Errorf("finish checked out %q", branch)
+	}
+	if head := r.git(r.dir, "rev-parse", "HEAD"); head != sha {
+		t.Errorf("master is at %s, want the merged %s", head, sha)
+	}
+	if branches := r.git(r.dir, "branch", "--list", "ISSUE-1"); branches != "" {
+		t.Error("the local branch was not deleted")
+	}
+	if r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the branch was not deleted on GitHub")
+	}
+}
+
+func TestFinishKeepsUnmergedCommits(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	mergeTestPR(t, r)
+	r.commit("footer.html", "<footer>\n")
+
+	out := r.mustRun("finish", "1")
+	if !strings.Contains(out, "deleting the local branch ISSUE-1: it has 1 commit(s) that are not in #2") {
+		t.Errorf("finish did not keep the local branch:\n%s", out)
+	}
+	if strings.Contains(out, "ISSUE-1 is finished") {
+		t.Errorf("finish reported success:\n%s", out)
+	}
+	if branches := r.git(r.dir, "branch", "--list", "ISSUE-1"); branches == "" {
+		t.Error("the local branch was deleted")
+	}
+	if r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the merged branch was not deleted on GitHub")
+	}
+	if status := itemStatus(r, project, 1); status != "Done" {
+		t.Errorf("the issue is in %q", status)
+	}
+}
+
+func TestFinishRefusesOpenPR(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.mustRun("pr")
+
+	if _, err := r.run("finish"); err == nil || !strings.Contains(err.Error(), "the pull request #2 from ISSUE-1 is not merged yet") {
+		t.Errorf("finish error = %v", err)
+	}
+	if status := itemStatus(r, project, 1); status != "In progress" {
+		t.Errorf("the issue was moved to %q", status)
+	}
+	if !r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the branch was deleted on GitHub")
+	}
+}
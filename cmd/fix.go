@@ -4,23 +4,23 @@ import (
 	"errors"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
-	"os/exec"
-	"strconv"
 )
 
 var (
 	noCloseAfterFix bool
+	recreateFix     bool
 	fixCmd          = &cobra.Command{
-		Use:   "fix",
-		Short: "open a new PR to fix a bug in the original one",
-		Long:  "Open a new PR to fix the original one. Add '-noclose' to override the closing of the issue",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runFix,
+		Use:         "fix [ISSUE_NUMBER]",
+		Short:       "open a new PR to fix a bug in the original one",
+		Long:        "Open a new PR to fix the original one. Add '-noclose' to override the closing of the issue, and '--recreate' to replace an open fix PR whose base branch changed",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{permissionsAnnotation: "repo,project:write"},
+		RunE:        runFix,
 	}
 )
 
 func runFix(cmd *cobra.Command, args []string) (err error) {
-	issueNum, err := strconv.Atoi(args[0])
+	issueNum, branchName, err := resolveIssue(cmd, args)
 	if err != nil {
 		return
 	}
@@ -28,16 +28,15 @@ func runFix(cmd *cobra.Command, args []string) (err error) {
 		err = errors.New("an invalid Issue was provided")
 		return
 	}
-	pushCmd := exec.Command("git", "matsuri", "save", args[0])
-	out, err := pushCmd.Output()
+	// save in-process so that the same backend is used
+	err = saveBranch(cmd, branchName)
 	if err != nil {
 		return
 	}
-	cmd.Println(string(out))
-	cmd.Printf("Creating a fix PR for ISSUE-%d...\n", issueNum)
-	pr, err := matsuri.CreateFixPRForIssueNumber(issueNum, noCloseAfterFix)
-	if pr != nil {
-		cmd.Printf("Pull Request created: %s\n", pr.GetHTMLURL())
+	cmd.Printf("Creating a fix PR for %s...\n", branchName)
+	opened, err := matsuri.CreateFixPRForIssueNumber(issueNum, branchName, noCloseAfterFix, recreateFix)
+	if opened != nil {
+		printPRResult(cmd, opened)
 	}
 	if err != nil {
 		return
@@ -49,5 +48,6 @@ func runFix(cmd *cobra.Command, args []string) (err error) {
 
 func init() {
 	fixCmd.Flags().BoolVar(&noCloseAfterFix, "noclose", false, "do not close Issue on merge")
+	fixCmd.Flags().BoolVar(&recreateFix, "recreate", false, "close the open PR and open a new one if its base branch changed")
 	rootCmd.AddCommand(fixCmd)
 }
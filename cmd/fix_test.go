@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	mergeTestPR(t, r)
+
+	r.git(r.dir, "checkout", "--quiet", "-b", "ISSUE-1-fix")
+	r.commit("header.html", "<header></header>\n")
+	out := r.mustRun("fix")
+	if !strings.Contains(out, "Pull Request created: https://github.com/MatsuriJapon/web/pull/3") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if !r.hasRemoteBranch("ISSUE-1-fix") {
+		t.Error("the fix branch was not pushed")
+	}
+	pulls := r.gh.PullRequests("web")
+	if len(pulls) != 2 {
+		t.Fatalf("%d pull requests were opened", len(pulls))
+	}
+	fix := pulls[1]
+	if fix.GetTitle() != "ISSUE-1-fix: Fix the header" || fix.GetBody() != "Fixes PR for #1\nCloses #1\n" || fix.GetHead().GetRef() != "ISSUE-1-fix" {
+		t.Errorf("the fix pull request is %q from %s: %q", fix.GetTitle(), fix.GetHead().GetRef(), fix.GetBody())
+	}
+	if state := r.gh.Issue("web", 1).GetState(); state != "open" {
+		t.Errorf("the issue is %s", state)
+	}
+
+	// running it again updates the fix pull request
+	out = r.mustRun("fix", "--noclose")
+	if !strings.Contains(out, "Pull Request updated: https://github.com/MatsuriJapon/web/pull/3") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if pulls = r.gh.PullRequests("web"); len(pulls) != 2 || pulls[1].GetBody() != "Fixes PR for #1\n" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+
+	// a regular pull request is not opened from the fix branch
+	if _, err := r.run("pr"); err == nil || !strings.Contains(err.Error(), "is a fix PR") {
+		t.Errorf("pr error = %v", err)
+	}
+	if pulls = r.gh.PullRequests("web"); len(pulls) != 2 {
+		t.Errorf("%d pull requests were opened", len(pulls))
+	}
+}
+
+func TestFixNoClose(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+
+	r.mustRun("fix", "--noclose", "1")
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 1 || pulls[0].GetBody() != "Fixes PR for #1\n" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
+
+func TestFixRefusesRegularPR(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.mustRun("pr")
+
+	r.commit("header.html", "<header></header>\n")
+	if _, err := r.run("fix"); err == nil || !strings.Contains(err.Error(), "#2 is already open from ISSUE-1 and is not a fix PR") {
+		t.Errorf("fix error = %v", err)
+	}
+	pulls := r.gh.PullRequests("web")
+	if len(pulls) != 1 || pulls[0].GetTitle() != "ISSUE-1: Fix the header" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
@@ -1,27 +1,64 @@
 package cmd
 
 import (
+	"os"
+	"time"
+
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
 )
 
 var (
-	kanbanCmd = &cobra.Command{
-		Use:   "kanban",
-		Short: "show the Kanban for the current year",
-		RunE:  runKanban,
+	kanbanLimit int
+	kanbanHTML  string
+	kanbanCmd   = &cobra.Command{
+		Use:         "kanban [YEAR]",
+		Short:       "show the Kanban for the current year",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{permissionsAnnotation: "repo,project:read"},
+		RunE:        runKanban,
 	}
 )
 
 func runKanban(cmd *cobra.Command, args []string) (err error) {
+	if err = yearArg(cmd, args); err != nil {
+		return
+	}
 	project, err := matsuri.GetProject()
 	if err != nil {
 		return
 	}
-	matsuri.PrintProjectKanban(project)
+	if kanbanHTML != "" {
+		return writeKanbanHTML(cmd, project)
+	}
+	err = matsuri.PrintProjectKanban(project, kanbanLimit, outputFormat)
+	return
+}
+
+// writeKanbanHTML writes the kanban as an HTML page to the file given with --html.
+func writeKanbanHTML(cmd *cobra.Command, project *matsuri.Project) (err error) {
+	report, err := matsuri.GetKanbanReport(project, kanbanLimit)
+	if report == nil {
+		return
+	}
+	f, createErr := os.Create(kanbanHTML)
+	if createErr != nil {
+		return createErr
+	}
+	if writeErr := report.WriteHTML(f, time.Now()); writeErr != nil {
+		f.Close()
+		return writeErr
+	}
+	if closeErr := f.Close(); closeErr != nil {
+		return closeErr
+	}
+	cmd.Printf("Kanban written to %s\n", kanbanHTML)
+	// issues that could not be fetched are reported once the page is written
 	return
 }
 
 func init() {
+	kanbanCmd.Flags().StringVar(&kanbanHTML, "html", "", "write the kanban to this file as an HTML page")
+	kanbanCmd.Flags().IntVar(&kanbanLimit, "limit", 0, "show at most this many cards per column (0 shows all)")
 	rootCmd.AddCommand(kanbanCmd)
 }
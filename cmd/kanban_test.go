@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+)
+
+// captureStdout runs f and returns what it wrote to the standard output, where the reports are printed.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	file, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	stdout := os.Stdout
+	os.Stdout = file
+	defer func() { os.Stdout = stdout }()
+	f()
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+// newTestKanban fills the board with issues 1 to 3 in to do, 4 in progress and 5 done.
+func newTestKanban(r *testRepo) {
+	project := newTestProject(r)
+	statuses := []string{"To do", "To do", "In progress", "Done"}
+	for i, status := range statuses {
+		r.gh.AddIssue("web", fmt.Sprintf("Issue %d", i+2))
+		r.gh.AddItem(project, "web", i+2, status)
+	}
+}
+
+// kanbanColumns lists the issue numbers of each column of a json kanban report.
+func kanbanColumns(t *testing.T, out string) (columns map[string][]int) {
+	t.Helper()
+	var report matsuri.KanbanReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid report: %v\n%s", err, out)
+	}
+	if report.Project != "Matsuri 2024" {
+		t.Errorf("the report is for %q", report.Project)
+	}
+	columns = map[string][]int{}
+	for _, column := range report.Columns {
+		columns[column.Name] = []int{}
+		for _, issue := range column.Issues {
+			columns[column.Name] = append(columns[column.Name], issue.Number)
+		}
+	}
+	return
+}
+
+func TestKanban(t *testing.T) {
+	r := newTestRepo(t)
+	newTestKanban(r)
+	tests := []struct {
+		args []string
+		want map[string][]int
+	}{
+		{
+			args: []string{"kanban", "-o", "json"},
+			want: map[string][]int{"To do": {1, 2, 3}, "In progress": {4}, "Done": {5}},
+		},
+		{
+			args: []string{"kanban", "-o", "json", "--limit", "2"},
+			want: map[string][]int{"To do": {1, 2}, "In progress": {4}, "Done": {5}},
+		},
+		{
+			args: []string{"kanban", "--year", "2024", "-o", "json", "2024"},
+			want: map[string][]int{"To do": {1, 2, 3}, "In progress": {4}, "Done": {5}},
+		},
+	}
+	for _, tt := range tests {
+		var err error
+		out := captureStdout(t, func() { _, err = r.run(tt.args...) })
+		if err != nil {
+			t.Fatalf("git matsuri %s: %v", strings.Join(tt.args, " "), err)
+		}
+		if got := kanbanColumns(t, out); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("git matsuri %s = %v, want %v", strings.Join(tt.args, " "), got, tt.want)
+		}
+	}
+}
+
+func TestKanbanText(t *testing.T) {
+	r := newTestRepo(t)
+	newTestKanban(r)
+	out := captureStdout(t, func() { r.mustRun("kanban") })
+	want := "To do\n1 [web]: Fix the header\n2 [web]: Issue 2\n3 [web]: Issue 3\n\nIn progress\n4 [web]: Issue 4\n\nDone\n5 [web]: Issue 5\n\n"
+	if out != want {
+		t.Errorf("kanban =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestKanbanLimit(t *testing.T) {
+	r := newTestRepo(t)
+	newTestKanban(r)
+	out := captureStdout(t, func() { r.mustRun("kanban", "--limit", "2") })
+	want := "To do\n1 [web]: Fix the header\n2 [web]: Issue 2\n\nIn progress\n4 [web]: Issue 4\n\nDone\n5 [web]: Issue 5\n\n"
+	if out != want {
+		t.Errorf("kanban --limit 2 =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestKanbanHTML(t *testing.T) {
+	r := newTestRepo(t)
+	newTestKanban(r)
+	path := filepath.Join(t.TempDir(), "kanban.html")
+	out := r.mustRun("kanban", "--html", path)
+	if !strings.Contains(out, "Kanban written to "+path) {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	page, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"Matsuri 2024", "In progress", "Fix the header", "Issue 5"} {
+		if !strings.Contains(string(page), text) {
+			t.Errorf("the page lacks %q", text)
+		}
+	}
+}
+
+func TestKanbanReadsEveryPage(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	// more cards than fit in a page
+	for i := 2; i <= 150; i++ {
+		r.gh.AddIssue("web", fmt.Sprintf("Issue %d", i))
+		r.gh.AddItem(project, "web", i, "To do")
+	}
+	out := captureStdout(t, func() { r.mustRun("kanban") })
+	if lines := strings.Count(out, " [web]: "); lines != 150 {
+		t.Errorf("kanban showed %d cards, want 150", lines)
+	}
+	if !strings.Contains(out, "150 [web]: Issue 150\n") {
+		t.Errorf("kanban lacks the last card:\n%s", out)
+	}
+}
+
+func TestKanbanReportsMissingIssues(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestClassicProject(r)
+	r.gh.AddIssue("web", "Issue 2")
+	r.gh.AddCard(r.gh.Column(project, "In progress"), "web", 2)
+	// a card whose issue cannot be fetched
+	r.gh.AddCard(r.gh.Column(project, "In progress"), "web", 42)
+	var err error
+	out := captureStdout(t, func() { _, err = r.run("kanban", "--concurrency", "1") })
+	if err == nil || !strings.Contains(err.Error(), "1 issue(s) could not be fetched") || !strings.Contains(err.Error(), "web#42") {
+		t.Errorf("kanban error = %v", err)
+	}
+	// the other cards are still shown
+	if want := "To do\n1 [web]: Fix the header\n\nIn progress\n2 [web]: Issue 2\n\nDone\n\n"; out != want {
+		t.Errorf("kanban =\n%s\nwant\n%s", out, want)
+	}
+}
+
+func TestKanbanYear(t *testing.T) {
+	r := newTestRepo(t)
+	newTestKanban(r)
+	next := r.gh.AddProjectV2("Matsuri 2025", "To do", "In progress", "Done")
+	r.gh.AddIssue("web", "Plan the stage")
+	r.gh.AddItem(next, "web", 6, "To do")
+
+	out := captureStdout(t, func() { r.mustRun("kanban", "2025") })
+	if out != "To do\n6 [web]: Plan the stage\n\nIn progress\n\nDone\n\n" {
+		t.Errorf("kanban 2025 printed:\n%s", out)
+	}
+	out = captureStdout(t, func() { r.mustRun("kanban", "--year", "2024") })
+	if !strings.Contains(out, "1 [web]: Fix the header") || strings.Contains(out, "Plan the stage") {
+		t.Errorf("kanban --year 2024 printed:\n%s", out)
+	}
+	if _, err := r.run("kanban", "2023"); err == nil {
+		t.Error("kanban showed a project for 2023")
+	}
+	if _, err := r.run("kanban", "--year", "2023", "2024"); err == nil || !strings.Contains(err.Error(), "conflicts with --year") {
+		t.Errorf("kanban error = %v", err)
+	}
+	if _, err := r.run("kanban", "soon"); err == nil || !strings.Contains(err.Error(), "invalid year") {
+		t.Errorf("kanban error = %v", err)
+	}
+}
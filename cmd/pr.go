@@ -4,24 +4,37 @@ import (
 	"errors"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
-	"os/exec"
-	"strconv"
 )
 
 var (
 	noCloseAfterPR bool
+	recreatePR     bool
 	prCmd          = &cobra.Command{
-		Use:               "pr",
-		Short:             "open a pull request for ISSUE",
-		Long:              "Open a pull request for ISSUE, adding a mention to $ISSUE in the message to link the PR to the issue. Add '-noclose' to override the closing of the issue",
-		Args:              cobra.ExactArgs(1),
+		Use:   "pr [ISSUE_NUMBER]",
+		Short: "open a pull request for ISSUE",
+		Long: "Open a pull request for ISSUE, adding a mention to $ISSUE in the message to link the PR to the issue. Add '-noclose' to override the closing of the issue. " +
+			"If the branch already has an open PR, its title and body are updated instead, and '--recreate' replaces it when the base branch changed",
+		Args:              cobra.MaximumNArgs(1),
+		Annotations:       map[string]string{permissionsAnnotation: "repo,project:write"},
 		RunE:              runPR,
 		ValidArgsFunction: completeInProgressIssuesForProject,
 	}
 )
 
+// printPRResult shows the URL of the PR, and warns when an existing PR targets another base branch than new ones would.
+func printPRResult(cmd *cobra.Command, opened *matsuri.OpenedPR) {
+	if !opened.Existing {
+		cmd.Printf("Pull Request created: %s\n", opened.GetHTMLURL())
+		return
+	}
+	cmd.Printf("Pull Request updated: %s\n", opened.GetHTMLURL())
+	if base := opened.GetBase().GetRef(); base != opened.WantedBase {
+		cmd.Printf("WARN: the PR is based on %s instead of %s, run again with --recreate to replace it\n", base, opened.WantedBase)
+	}
+}
+
 func runPR(cmd *cobra.Command, args []string) (err error) {
-	issueNum, err := strconv.Atoi(args[0])
+	issueNum, branchName, err := resolveIssue(cmd, args)
 	if err != nil {
 		return
 	}
@@ -29,22 +42,22 @@ func runPR(cmd *cobra.Command, args []string) (err error) {
 		err = errors.New("an invalid Issue number was provided")
 		return
 	}
-	pushCmd := exec.Command("git", "matsuri", "save", args[0])
-	out, err := pushCmd.Output()
+	// save in-process so that the same backend is used
+	err = saveBranch(cmd, branchName)
 	if err != nil {
 		return
 	}
-	cmd.Println(string(out))
-	cmd.Printf("Creating a PR for ISSUE-%d...\n", issueNum)
-	pr, err := matsuri.CreatePRForIssueNumber(issueNum, noCloseAfterPR)
+	cmd.Printf("Creating a PR for %s...\n", branchName)
+	opened, err := matsuri.CreatePRForIssueNumber(issueNum, branchName, noCloseAfterPR, recreatePR)
 	// we might succeed at creating the PR but fail at placing it in the To Do column
-	if pr != nil {
-		cmd.Printf("Pull Request created: %s\n", pr.GetHTMLURL())
+	if opened != nil {
+		printPRResult(cmd, opened)
 	}
 	return
 }
 
 func init() {
 	prCmd.Flags().BoolVar(&noCloseAfterPR, "noclose", false, "do not close Issue on merge")
+	prCmd.Flags().BoolVar(&recreatePR, "recreate", false, "close the open PR and open a new one if its base branch changed")
 	rootCmd.AddCommand(prCmd)
 }
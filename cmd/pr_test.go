@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPR(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+
+	out := r.mustRun("pr")
+	if !strings.Contains(out, "Pull Request created: https://github.com/MatsuriJapon/web/pull/2") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if !r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the branch was not pushed")
+	}
+	pulls := r.gh.PullRequests("web")
+	if len(pulls) != 1 {
+		t.Fatalf("%d pull requests were opened", len(pulls))
+	}
+	pr := pulls[0]
+	if pr.GetTitle() != "ISSUE-1: Fix the header" || pr.GetBody() != "Closes #1\n" || pr.GetHead().GetRef() != "ISSUE-1" || pr.GetBase().GetRef() != "master" {
+		t.Errorf("the pull request is %q from %s to %s: %q", pr.GetTitle(), pr.GetHead().GetRef(), pr.GetBase().GetRef(), pr.GetBody())
+	}
+	if status := itemStatus(r, project, 2); status != "To do" {
+		t.Errorf("the pull request is in %q", status)
+	}
+
+	// running it again updates the pull request
+	out = r.mustRun("pr", "--noclose", "1")
+	if !strings.Contains(out, "Pull Request updated: https://github.com/MatsuriJapon/web/pull/2") || strings.Contains(out, "WARN") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	pulls = r.gh.PullRequests("web")
+	if len(pulls) != 1 || pulls[0].GetBody() != "Related to #1\n" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
+
+func TestPRNoClose(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+
+	r.mustRun("pr", "--noclose", "1")
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 1 || pulls[0].GetBody() != "Related to #1\n" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
+
+func TestPRBaseChanged(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.mustRun("pr")
+
+	// the year branch of the festival appears, new pull requests are opened against it
+	r.git(r.dir, "push", "--quiet", "origin", "master:v2024")
+	out := r.mustRun("pr")
+	if !strings.Contains(out, "WARN: the PR is based on master instead of v2024, run again with --recreate to replace it") {
+		t.Errorf("pr did not warn about the base branch:\n%s", out)
+	}
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 1 || pulls[0].GetBase().GetRef() != "master" {
+		t.Fatalf("the pull requests are %v", pulls)
+	}
+
+	out = r.mustRun("pr", "--recreate")
+	if !strings.Contains(out, "Pull Request created: https://github.com/MatsuriJapon/web/pull/3") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	pulls := r.gh.PullRequests("web")
+	if len(pulls) != 2 {
+		t.Fatalf("%d pull requests were opened", len(pulls))
+	}
+	if pulls[0].GetState() != "closed" {
+		t.Errorf("the first pull request is %s", pulls[0].GetState())
+	}
+	if pulls[1].GetState() != "open" || pulls[1].GetBase().GetRef() != "v2024" {
+		t.Errorf("the new pull request is %s against %s", pulls[1].GetState(), pulls[1].GetBase().GetRef())
+	}
+}
+
+func TestPRYearBranch(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	// the repository has a branch for the festival year of the project
+	r.git(r.dir, "push", "--quiet", "origin", "master:v2024")
+
+	out := r.mustRun("start", "1")
+	if !strings.Contains(out, "Checking out v2024...") {
+		t.Errorf("start did not start from the year branch:\n%s", out)
+	}
+	r.commit("header.html", "<header>\n")
+	r.mustRun("pr")
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 1 || pulls[0].GetBase().GetRef() != "v2024" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
+
+func TestPRWithoutIssue(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	_, err := r.run("pr")
+	if err == nil || !strings.Contains(err.Error(), "no issue was chosen (there is nothing to choose from)") {
+		t.Errorf("pr error = %v", err)
+	}
+}
+
+func TestPRPicksIssue(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.git(r.dir, "checkout", "--quiet", "master")
+
+	r.input = "1\n"
+	out := r.mustRun("pr")
+	if !strings.Contains(out, "  1) #1 Fix the header\n") {
+		t.Errorf("pr did not list the issues in progress:\n%s", out)
+	}
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 1 || pulls[0].GetHead().GetRef() != "ISSUE-1" {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
+
+func TestPRClosedIssue(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	closeIssue(t, r, 1)
+	if _, err := r.run("pr", "1"); err == nil {
+		t.Error("pr accepted a closed issue")
+	}
+	if r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the branch was pushed")
+	}
+}
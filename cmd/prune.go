@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneDryRun bool
+	pruneCmd    = &cobra.Command{
+		Use:   "prune",
+		Short: "delete the topic branches of merged and abandoned issues",
+		Long: "List the topic branches, locally and on GitHub, with the state of their issue and pull requests: " +
+			"merged, closed without being merged, still open, or orphaned when the issue was deleted or transferred. " +
+			"Nothing is deleted unless --dry-run=false is given, in which case you choose which states to delete.",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{permissionsAnnotation: "repo"},
+		RunE:        runPrune,
+	}
+)
+
+// topicBranch is a topic branch found locally, on origin, or both.
+type topicBranch struct {
+	*matsuri.BranchState
+	local, remote bool
+}
+
+func (b *topicBranch) where() string {
+	switch {
+	case b.local && b.remote:
+		return "local, origin"
+	case b.local:
+		return "local"
+	}
+	return "origin"
+}
+
+// listTopicBranches gets the topic branches under the given ref prefix.
+func listTopicBranches(prefix string) (branches []string, err error) {
+	out, err := git.Output("", "for-each-ref", "--format=%(refname)", prefix)
+	if err != nil {
+		return
+	}
+	for _, ref := range strings.Fields(out) {
+		branch := strings.TrimPrefix(ref, prefix)
+		if _, ok := matsuri.IssueNumberFromBranch(branch); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return
+}
+
+// confirm asks a yes or no question, defaulting to no.
+func confirm(cmd *cobra.Command, reader *bufio.Reader, question string) bool {
+	cmd.Printf("%s [y/N] ", question)
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		cmd.Println()
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// deleteTopicBranch deletes a topic branch locally and on origin, unless it has commits that were not merged.
+func deleteTopicBranch(cmd *cobra.Command, b *topicBranch, current string) {
+	refs := map[bool]string{true: "refs/heads/" + b.Branch, false: "refs/remotes/origin/" + b.Branch}
+	for _, local := range []bool{true, false} {
+		if local && !b.local || !local && !b.remote {
+			continue
+		}
+		where := "locally"
+		if !local {
+			where = "on GitHub"
+		}
+		if local && b.Branch == current {
+			cmd.Printf("WARN: %s is checked out, it was not deleted\n", b.Branch)
+			continue
+		}
+		if b.State == matsuri.BranchMerged {
+			if reason := unmergedCommits(refs[local], b.Head, b.PullRequest); reason != "" {
+				cmd.Printf("WARN: %s was not deleted %s, %s\n", b.Branch, where, reason)
+				continue
+			}
+		}
+		cmd.Printf("Deleting %s %s...\n", b.Branch, where)
+		var err error
+		if local {
+			_, err = git.Run("", "branch", "--delete", "--force", b.Branch)
+		} else {
+			_, err = git.Run("", "push", "origin", "--delete", b.Branch)
+		}
+		if err != nil {
+			cmd.Printf("WARN: %s\n", err)
+		}
+	}
+}
+
+func runPrune(cmd *cobra.Command, args []string) (err error) {
+	cmd.Println("Fetching changes...")
+	if _, err = git.RunWithProgress(cmd.ErrOrStderr(), "", "fetch", "--prune", "origin"); err != nil {
+		return
+	}
+	local, err := listTopicBranches("refs/heads/")
+	if err != nil {
+		return
+	}
+	remote, err := listTopicBranches("refs/remotes/origin/")
+	if err != nil {
+		return
+	}
+	byName := map[string]*topicBranch{}
+	var names []string
+	for _, list := range [][]string{local, remote} {
+		for _, name := range list {
+			if byName[name] == nil {
+				byName[name] = &topicBranch{}
+				names = append(names, name)
+			}
+		}
+	}
+	for _, name := range local {
+		byName[name].local = true
+	}
+	for _, name := range remote {
+		byName[name].remote = true
+	}
+	if len(names) == 0 {
+		cmd.Println("There are no topic branches")
+		return
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, _ := matsuri.IssueNumberFromBranch(names[i])
+		b, _ := matsuri.IssueNumberFromBranch(names[j])
+		return a < b || a == b && names[i] < names[j]
+	})
+
+	cmd.Printf("Looking up the issues and pull requests of %d branch(es)...\n", len(names))
+	states, lookupErr := matsuri.GetBranchStates(names)
+	if lookupErr != nil {
+		cmd.Printf("WARN: these branches are left out: %s\n", matsuri.DescribeError(lookupErr).Error())
+	}
+	byState := map[string][]*topicBranch{}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "BRANCH\tWHERE\tISSUE\tPULL REQUEST\tSTATE")
+	for _, state := range states {
+		b := byName[state.Branch]
+		b.BranchState = state
+		byState[state.State] = append(byState[state.State], b)
+		pr := "-"
+		if state.PullRequest != 0 {
+			pr = fmt.Sprintf("#%d", state.PullRequest)
+		}
+		fmt.Fprintf(w, "%s\t%s\t#%d\t%s\t%s\n", state.Branch, b.where(), state.Issue, pr, state.State)
+	}
+	if err = w.Flush(); err != nil {
+		return
+	}
+	var counts []string
+	for _, state := range matsuri.BranchStates {
+		if n := len(byState[state]); n != 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	cmd.Println(strings.Join(counts, ", "))
+
+	if pruneDryRun {
+		cmd.Println("Nothing was deleted, run `git matsuri prune --dry-run=false` to choose which branches to delete")
+		return
+	}
+	current, err := git.CurrentBranch("")
+	if err != nil {
+		return
+	}
+	reader := bufio.NewReader(cmd.InOrStdin())
+	var chosen []string
+	for _, state := range matsuri.BranchStates {
+		n := len(byState[state])
+		if n == 0 {
+			continue
+		}
+		question := fmt.Sprintf("Delete the %d %s branch(es)?", n, state)
+		if state == matsuri.BranchOpen {
+			question = fmt.Sprintf("Delete the %d %s branch(es)? Someone may still be working on them.", n, state)
+		}
+		if confirm(cmd, reader, question) {
+			chosen = append(chosen, state)
+		}
+	}
+	if len(chosen) == 0 {
+		cmd.Println("Nothing was deleted")
+		return
+	}
+	for _, state := range chosen {
+		for _, b := range byState[state] {
+			deleteTopicBranch(cmd, b, current)
+		}
+	}
+	return
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", true, "only list the branches, set to false to delete some of them")
+	rootCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// newPruneTestRepo merges the pull request of issue 1, and leaves issue 3 open with its branch on GitHub.
+func newPruneTestRepo(t *testing.T) *testRepo {
+	r := newTestRepo(t)
+	newTestProject(r)
+	mergeTestPR(t, r)
+	r.gh.AddIssue("web", "Fix the footer")
+	r.git(r.dir, "checkout", "--quiet", "-b", "ISSUE-3", "master")
+	r.commit("footer.html", "<footer>\n")
+	r.git(r.dir, "push", "--quiet", "-u", "origin", "ISSUE-3")
+	r.git(r.dir, "checkout", "--quiet", "master")
+	return r
+}
+
+// hasBranch reports whether the clone has the given branch.
+func (r *testRepo) hasBranch(branch string) bool {
+	r.t.Helper()
+	return r.git(r.dir, "branch", "--list", branch) != ""
+}
+
+func TestPrune(t *testing.T) {
+	r := newPruneTestRepo(t)
+
+	out := r.mustRun("prune")
+	if !strings.Contains(out, "1 merged, 1 open") || !strings.Contains(out, "Nothing was deleted") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if !r.hasBranch("ISSUE-1") || !r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the dry run deleted ISSUE-1")
+	}
+
+	// only the merged branch is chosen
+	r.input = "y\nn\n"
+	out = r.mustRun("prune", "--dry-run=false")
+	if r.hasBranch("ISSUE-1") || r.hasRemoteBranch("ISSUE-1") {
+		t.Errorf("ISSUE-1 was not deleted:\n%s", out)
+	}
+	if !r.hasBranch("ISSUE-3") || !r.hasRemoteBranch("ISSUE-3") {
+		t.Errorf("the open branch ISSUE-3 was deleted:\n%s", out)
+	}
+}
+
+func TestPruneKeepsUnmergedCommits(t *testing.T) {
+	r := newPruneTestRepo(t)
+	// a commit was added after the pull request was merged
+	r.git(r.dir, "checkout", "--quiet", "ISSUE-1")
+	r.commit("header.html", "<header>\n<h1>Matsuri</h1>\n")
+	r.git(r.dir, "push", "--quiet", "origin", "ISSUE-1")
+	r.git(r.dir, "checkout", "--quiet", "master")
+
+	r.input = "y\nn\n"
+	out := r.mustRun("prune", "--dry-run=false")
+	for _, where := range []string{"locally", "on GitHub"} {
+		if !strings.Contains(out, "WARN: ISSUE-1 was not deleted "+where+", it has 1 commit(s) that are not in #2") {
+			t.Errorf("prune did not warn about the commit %s:\n%s", where, out)
+		}
+	}
+	if !r.hasBranch("ISSUE-1") || !r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the unmerged commit of ISSUE-1 was deleted")
+	}
+}
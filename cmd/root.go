@@ -1,13 +1,16 @@
 package cmd
 
 import (
-	"errors"
+	"fmt"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
 	"os"
+	"strings"
 )
 
 var (
+	noCache      bool
+	outputFormat string
 	// CurrentVersion is a build-time string representing the current version.
 	rootCmd = &cobra.Command{
 		Use:               "git-matsuri",
@@ -16,19 +19,64 @@ var (
 	}
 )
 
+// applyGlobalFlags checks and applies the persistent flags that do not need a token or a repository.
+func applyGlobalFlags(cmd *cobra.Command, args []string) (err error) {
+	if err = matsuri.ValidateFormat(outputFormat); err != nil {
+		return
+	}
+	matsuri.CacheEnabled = !noCache
+	return
+}
+
 func sanity(cmd *cobra.Command, args []string) (err error) {
+	if err = applyGlobalFlags(cmd, args); err != nil {
+		return
+	}
+	config, err := matsuri.LoadConfig()
+	if err != nil {
+		return
+	}
+	if err = matsuri.SetConfig(config); err != nil {
+		return
+	}
 	if _, err := matsuri.GetRepoName(); err != nil {
 		cmd.Println("WARN: You are currently not in a git repository, some subcommands may not run.")
 	}
-	if token := os.Getenv(matsuri.TokenName); token == "" {
-		err = errors.New("gitHub token not found.\nPlease create one at https://github.com/settings/tokens/new with 'repo' and 'user:email' permissions and save it to your system environment variables under the name MATSURI_TOKEN")
+	if matsuri.HasBackend() {
+		// a Backend was injected, e.g. an in-memory fake, so no token is needed
+		return
 	}
+	if _, _, err = matsuri.FindToken(); err != nil {
+		err = fmt.Errorf("%s.\nPlease create one at https://github.com/settings/tokens/new with 'repo', 'user:email' and 'project' permissions and save it with `git matsuri auth login`, or in your system environment variables under the name MATSURI_TOKEN", err.Error())
+		return
+	}
+	b, err := matsuri.NewDefaultBackend()
+	if err != nil {
+		err = fmt.Errorf("invalid %s: %s", matsuri.APIURLName, err.Error())
+		return
+	}
+	matsuri.SetBackend(b)
+	err = matsuri.ValidateToken(cmd.CommandPath(), requiredPermissions(cmd))
 	return
 }
 
+// ExecuteWith runs git-matsuri with the given arguments against the given Backend.
+func ExecuteWith(b *matsuri.Backend, args []string) error {
+	matsuri.SetBackend(b)
+	rootCmd.SetArgs(args)
+	return rootCmd.Execute()
+}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "do not use or update the cache of GitHub API responses")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", matsuri.FormatText, "output format of todo and kanban: "+strings.Join(matsuri.Formats, ", "))
+	rootCmd.PersistentFlags().IntVar(&matsuri.Year, "year", 0, "use the project of this festival year, even if it is closed")
+	rootCmd.PersistentFlags().IntVar(&matsuri.Concurrency, "concurrency", matsuri.Concurrency, "maximum number of issues fetched from GitHub at the same time")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		rootCmd.PrintErr(err)
+		rootCmd.PrintErr(matsuri.DescribeError(err))
 		os.Exit(1)
 	}
 }
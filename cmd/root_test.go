@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/MatsuriJapon/git-matsuri/matsuri/fake"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// testRepo is a clone of the web repository, whose origin is a bare repository standing in for GitHub.
+type testRepo struct {
+	t      *testing.T
+	gh     *fake.GitHub
+	dir    string
+	origin string
+	// input is what git-matsuri reads from its standard input.
+	input string
+}
+
+// newTestRepo creates the web repository on the fake and on disk, and makes its clone the current directory.
+// Git and git-matsuri only see the settings of a temporary home directory.
+func newTestRepo(t *testing.T) *testRepo {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	if err := os.Mkdir(home, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, env := range []string{"HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(env, home)
+	}
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("LC_ALL", "C")
+	r := &testRepo{t: t, gh: fake.New(), dir: filepath.Join(root, "web"), origin: filepath.Join(root, "origin.git")}
+	r.gh.AddRepo("web", "master")
+
+	r.git(root, "init", "--quiet", "--bare", r.origin)
+	r.git(root, "config", "--global", "user.name", "Volunteer")
+	r.git(root, "config", "--global", "user.email", "volunteer@festivaljapon.com")
+	r.git(root, "config", "--global", "init.defaultBranch", "master")
+	// the remote keeps its GitHub URL, which git-matsuri reads, while git talks to the bare repository
+	r.git(root, "config", "--global", "url."+r.origin+".insteadOf", "git@github.com:MatsuriJapon/web.git")
+	r.git(root, "init", "--quiet", r.dir)
+	r.git(r.dir, "remote", "add", "origin", "git@github.com:MatsuriJapon/web.git")
+	r.commit("README.md", "web\n")
+	r.git(r.dir, "push", "--quiet", "-u", "origin", "master")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(r.dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return r
+}
+
+// git runs git in dir and returns its trimmed output, failing the test on errors.
+func (r *testRepo) git(dir string, args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes a file of the clone and commits it, returning the commit hash.
+func (r *testRepo) commit(name, content string) string {
+	r.t.Helper()
+	if err := os.WriteFile(filepath.Join(r.dir, name), []byte(content), 0o600); err != nil {
+		r.t.Fatal(err)
+	}
+	r.git(r.dir, "add", name)
+	r.git(r.dir, "commit", "--quiet", "-m", "Update "+name)
+	return r.git(r.dir, "rev-parse", "HEAD")
+}
+
+// userConfig writes the user configuration file of git-matsuri.
+func (r *testRepo) userConfig(content string) {
+	r.t.Helper()
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	path := filepath.Join(dir, matsuri.UserConfigPath)
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		r.t.Fatal(err)
+	}
+	if err = os.WriteFile(path, []byte(content), 0o600); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// hasRemoteBranch reports whether the origin has the given branch.
+func (r *testRepo) hasRemoteBranch(branch string) bool {
+	r.t.Helper()
+	return r.git(r.dir, "ls-remote", "--heads", "origin", branch) != ""
+}
+
+// failGit makes the git commands starting with args fail with stderr and kind, as they would against GitHub,
+// while the other commands keep running git.
+func (r *testRepo) failGit(kind error, stderr string, args ...string) {
+	r.t.Helper()
+	previous := git.Default
+	git.Default = git.RunnerFunc(func(c git.Command) (string, error) {
+		if len(c.Args) >= len(args) && strings.Join(c.Args[:len(args)], " ") == strings.Join(args, " ") {
+			return "", &git.Error{Args: c.Args, Stderr: stderr, Kind: kind, Err: errors.New("exit status 128")}
+		}
+		return previous.Run(c)
+	})
+	r.t.Cleanup(func() { git.Default = previous })
+}
+
+// run runs git-matsuri with args against the fake and returns everything it printed.
+func (r *testRepo) run(args ...string) (string, error) {
+	r.t.Helper()
+	resetFlags(rootCmd)
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetIn(strings.NewReader(r.input))
+	err := ExecuteWith(r.gh.Backend(), args)
+	return out.String(), err
+}
+
+// mustRun runs git-matsuri like run, failing the test on errors.
+func (r *testRepo) mustRun(args ...string) string {
+	r.t.Helper()
+	out, err := r.run(args...)
+	if err != nil {
+		r.t.Fatalf("git matsuri %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// resetFlags brings back the default value of every flag, as cobra keeps the values of previous runs
+// and some arguments, like the year of kanban, are stored in flag variables.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if value := strings.Trim(f.DefValue, "[]"); value != "" {
+				values = strings.Split(value, ",")
+			}
+			_ = slice.Replace(values)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, child := range cmd.Commands() {
+		resetFlags(child)
+	}
+}
@@ -3,24 +3,24 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
-	"os/exec"
-	"strconv"
 )
 
 var (
 	saveCmd = &cobra.Command{
-		Use:               "save",
+		Use:               "save [ISSUE_NUMBER]",
 		Short:             "save current work on GitHub",
-		Args:              cobra.ExactArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		Annotations:       map[string]string{permissionsAnnotation: "repo"},
 		RunE:              runSave,
 		ValidArgsFunction: completeInProgressIssuesForProject,
 	}
 )
 
 func runSave(cmd *cobra.Command, args []string) (err error) {
-	issue, err := strconv.Atoi(args[0])
+	issue, branchName, err := resolveIssue(cmd, args)
 	if err != nil {
 		return
 	}
@@ -28,15 +28,19 @@ func runSave(cmd *cobra.Command, args []string) (err error) {
 		err = errors.New("the provided Issue doesn't exist")
 		return
 	}
+	return saveBranch(cmd, branchName)
+}
+
+// saveBranch pushes a topic branch to GitHub.
+func saveBranch(cmd *cobra.Command, branchName string) (err error) {
 	cmd.Println("Pushing your changes to GitHub...")
-	branches := fmt.Sprintf("ISSUE-%d:ISSUE-%d", issue, issue)
-	pushCmd := exec.Command("git", "push", "-u", "origin", branches)
-	out, err := pushCmd.Output()
+	branches := fmt.Sprintf("%s:%s", branchName, branchName)
+	out, err := git.RunWithProgress(cmd.ErrOrStderr(), "", "push", "-u", "origin", branches)
 	if err != nil {
-		err = fmt.Errorf("there was a problem pushing the branch: %s", err.Error())
+		err = fmt.Errorf("there was a problem pushing the branch: %w", err)
 		return
 	}
-	cmd.Println(string(out))
+	cmd.Print(out)
 	return
 }
 
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+)
+
+func TestSave(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+
+	r.mustRun("save")
+	if !r.hasRemoteBranch("ISSUE-1") {
+		t.Error("the branch was not pushed")
+	}
+}
+
+func TestSaveAuthenticationFailure(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.failGit(git.ErrAuthentication, "remote: Invalid username or password.\nfatal: Authentication failed", "push")
+
+	_, err := r.run("save")
+	if !errors.Is(err, git.ErrAuthentication) || !strings.Contains(err.Error(), "there was a problem pushing the branch") {
+		t.Errorf("save returned %v, want an authentication failure", err)
+	}
+
+	// pr stops before opening the pull request
+	_, err = r.run("pr")
+	if !errors.Is(err, git.ErrAuthentication) {
+		t.Errorf("pr returned %v, want an authentication failure", err)
+	}
+	if pulls := r.gh.PullRequests("web"); len(pulls) != 0 {
+		t.Errorf("the pull requests are %v", pulls)
+	}
+}
@@ -2,19 +2,19 @@ package cmd
 
 import (
 	"errors"
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
-	"os"
-	"os/exec"
 )
 
 var (
 	useHTTP  bool
 	setupCmd = &cobra.Command{
-		Use:   "setup",
-		Short: "clones a Matsuri repository",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runSetup,
+		Use:         "setup",
+		Short:       "clones a Matsuri repository",
+		Args:        cobra.ExactArgs(1),
+		Annotations: map[string]string{permissionsAnnotation: "repo,email"},
+		RunE:        runSetup,
 	}
 )
 
@@ -33,12 +33,11 @@ func runSetup(cmd *cobra.Command, args []string) (err error) {
 	if err != nil {
 		return
 	}
-	cloneCmd := exec.Command("git", "clone", cloneURL)
-	out, err := cloneCmd.CombinedOutput()
+	out, err := git.RunWithProgress(cmd.ErrOrStderr(), "", "clone", cloneURL, repoName)
 	if err != nil {
 		return
 	}
-	cmd.Println(out)
+	cmd.Print(out)
 
 	matsuriEmail, err := matsuri.GetMatsuriEmail()
 	if err != nil {
@@ -48,12 +47,7 @@ func runSetup(cmd *cobra.Command, args []string) (err error) {
 		err = errors.New("a Matsuri email address was not found in this account, please add one in your GitHun profile")
 		return
 	}
-	err = os.Chdir(repoName)
-	if err != nil {
-		return
-	}
-	configCmd := exec.Command("git", "config", "--local", "user.email", matsuriEmail)
-	err = configCmd.Run()
+	_, err = git.Run(repoName, "config", "--local", "user.email", matsuriEmail)
 	return
 }
 
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+// backupRefPrefix holds the heads of topic branches before they were squashed.
+const backupRefPrefix = "refs/matsuri/backup/"
+
+var (
+	squashUndo   bool
+	squashNoEdit bool
+	squashCmd    = &cobra.Command{
+		Use:   "squash",
+		Short: "squash the commits of the current topic branch into one",
+		Long: "Replace the commits of the current topic branch since it left the default branch with a single commit, " +
+			"titled after the issue. The message is opened in your editor unless --no-edit is given. " +
+			"The previous commits are kept under " + backupRefPrefix + "<branch>, and `git matsuri squash --undo` brings them back.",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{permissionsAnnotation: "repo"},
+		RunE:        runSquash,
+	}
+)
+
+// squashMessage is the default message of the squashed commit, listing the commits it replaces in comments.
+func squashMessage(issueNumber int, log string) string {
+	title := matsuri.BranchName(issueNumber)
+	if issue, err := matsuri.GetIssue(issueNumber); err == nil {
+		title += ": " + issue.GetTitle()
+	}
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	b.WriteString("# Squashing these commits into one:\n")
+	for _, line := range strings.Split(log, "\n") {
+		b.WriteString("#   " + line + "\n")
+	}
+	b.WriteString("# Lines starting with '#' are ignored, and an empty message cancels the squash.\n")
+	return b.String()
+}
+
+// checkSquashable makes sure that the current branch is a topic branch without uncommitted changes.
+func checkSquashable() (branch string, issueNumber int, err error) {
+	if branch, err = git.CurrentBranch(""); err != nil {
+		return
+	}
+	issueNumber, ok := matsuri.IssueNumberFromBranch(branch)
+	if !ok {
+		err = fmt.Errorf("the current branch %q is not named like an issue branch (%s)", branch, strings.Join(matsuri.BranchPatterns(), ", "))
+		return
+	}
+	status, err := git.GetStatus("")
+	if err != nil {
+		return
+	}
+	if !status.Clean() {
+		err = fmt.Errorf("%w:\n%s\nCommit or stash them before squashing", git.ErrDirtyTree, status)
+	}
+	return
+}
+
+func undoSquash(cmd *cobra.Command, branch string) (err error) {
+	backup := backupRefPrefix + branch
+	found, err := git.HasRef("", backup)
+	if err != nil {
+		return
+	}
+	if !found {
+		return fmt.Errorf("%s was not squashed, there is no %s", branch, backup)
+	}
+	cmd.Printf("Restoring %s from %s...\n", branch, backup)
+	if _, err = git.Run("", "reset", "--keep", backup); err != nil {
+		return
+	}
+	cmd.Printf("%s is back to its commits from before squashing, push it with `git push --force-with-lease` to update the pull request\n", branch)
+	return
+}
+
+func runSquash(cmd *cobra.Command, args []string) (err error) {
+	branch, issueNumber, err := checkSquashable()
+	if err != nil {
+		return
+	}
+	if squashUndo {
+		return undoSquash(cmd, branch)
+	}
+
+	base, err := matsuri.GetBaseBranch()
+	if err != nil {
+		return
+	}
+	upstream := "origin/" + *base
+	if remote, _ := git.HasRef("", "refs/remotes/"+upstream); !remote {
+		upstream = *base
+	}
+	mergeBase, err := git.Output("", "merge-base", "HEAD", upstream)
+	if err != nil {
+		return fmt.Errorf("%s has no common history with %s: %w", branch, upstream, err)
+	}
+	count, err := git.Output("", "rev-list", "--count", mergeBase+"..HEAD")
+	if err != nil {
+		return
+	}
+	if n, _ := strconv.Atoi(count); n < 2 {
+		return fmt.Errorf("%s has %s commit(s) since %s, there is nothing to squash", branch, count, upstream)
+	}
+	log, err := git.Output("", "log", "--reverse", "--format=%h %s", mergeBase+"..HEAD")
+	if err != nil {
+		return
+	}
+	head, err := git.Output("", "rev-parse", "HEAD")
+	if err != nil {
+		return
+	}
+
+	file, err := os.CreateTemp("", "git-matsuri-squash-*.txt")
+	if err != nil {
+		return
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(squashMessage(issueNumber, log))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return
+	}
+
+	backup := backupRefPrefix + branch
+	if _, err = git.Run("", "update-ref", "-m", "git-matsuri: before squashing", backup, head); err != nil {
+		return
+	}
+	cmd.Printf("Squashing %s commits onto %s...\n", count, upstream)
+	if _, err = git.Run("", "reset", "--soft", mergeBase); err != nil {
+		return
+	}
+	commit := git.Command{Args: []string{"commit", "--cleanup=strip", "--file", file.Name()}}
+	if !squashNoEdit {
+		commit.Args = append(commit.Args, "--edit")
+		commit.Interactive = true
+	}
+	if _, err = git.Default.Run(commit); err != nil {
+		// put the branch back as it was, the index still holds the same changes
+		if _, resetErr := git.Run("", "reset", "--soft", head); resetErr != nil {
+			return fmt.Errorf("the squash failed (%s), and %s could not be restored, run `git matsuri squash --undo`: %w", err, branch, resetErr)
+		}
+		return fmt.Errorf("the squash was cancelled: %w", err)
+	}
+	cmd.Printf("%s now has a single commit, the previous ones are saved in %s\n", branch, backup)
+	cmd.Println("Push it with `git push --force-with-lease` to update the pull request, or run `git matsuri squash --undo` to restore them")
+	return
+}
+
+func init() {
+	squashCmd.Flags().BoolVar(&squashUndo, "undo", false, "restores the commits from before the last squash")
+	squashCmd.Flags().BoolVar(&squashNoEdit, "no-edit", false, "uses the default message without opening an editor")
+	squashCmd.MarkFlagsMutuallyExclusive("undo", "no-edit")
+	rootCmd.AddCommand(squashCmd)
+}
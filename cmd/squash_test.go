@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// newSquashTestRepo starts issue 1 with two commits on top of master, and returns the heads of master and ISSUE-1.
+func newSquashTestRepo(t *testing.T) (r *testRepo, base, head string) {
+	r = newTestRepo(t)
+	newTestProject(r)
+	base = r.git(r.dir, "rev-parse", "master")
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	head = r.commit("footer.html", "<footer>\n")
+	return
+}
+
+func TestSquash(t *testing.T) {
+	r, base, head := newSquashTestRepo(t)
+
+	out := r.mustRun("squash", "--no-edit")
+	if !strings.Contains(out, "ISSUE-1 now has a single commit") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if parent := r.git(r.dir, "rev-parse", "HEAD^"); parent != base {
+		t.Errorf("the squashed commit is on top of %s, want master at %s", parent, base)
+	}
+	if message := r.git(r.dir, "log", "-1", "--format=%B"); message != "ISSUE-1: Fix the header" {
+		t.Errorf("the squashed commit is titled %q", message)
+	}
+	if diff := r.git(r.dir, "diff", head, "HEAD"); diff != "" {
+		t.Errorf("the squashed commit changed the files:\n%s", diff)
+	}
+	if backup := r.git(r.dir, "rev-parse", backupRefPrefix+"ISSUE-1"); backup != head {
+		t.Errorf("the backup is %s, want %s", backup, head)
+	}
+
+	r.mustRun("squash", "--undo")
+	if got := r.git(r.dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("squash --undo restored %s, want %s", got, head)
+	}
+}
+
+func TestSquashCancelled(t *testing.T) {
+	r, _, head := newSquashTestRepo(t)
+	// emptying the message cancels the commit
+	t.Setenv("GIT_EDITOR", ": >")
+
+	if _, err := r.run("squash"); err == nil || !strings.Contains(err.Error(), "the squash was cancelled") {
+		t.Errorf("squash returned %v", err)
+	}
+	if got := r.git(r.dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("the branch is at %s, want %s", got, head)
+	}
+	if status := r.git(r.dir, "status", "--porcelain"); status != "" {
+		t.Errorf("the squash left changes behind:\n%s", status)
+	}
+}
+
+func TestSquashSingleCommit(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+
+	if _, err := r.run("squash", "--no-edit"); err == nil || !strings.Contains(err.Error(), "there is nothing to squash") {
+		t.Errorf("squash returned %v", err)
+	}
+}
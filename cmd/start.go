@@ -3,60 +3,84 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"os/exec"
-	"regexp"
 	"strconv"
+	"strings"
 
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
 	"github.com/MatsuriJapon/git-matsuri/matsuri"
 	"github.com/spf13/cobra"
 )
 
 var (
-	startCmd = &cobra.Command{
-		Use:               "start ISSUE_NUMBER",
+	startStash  bool
+	startCarry  bool
+	startRebase bool
+	startForce  bool
+	startCmd    = &cobra.Command{
+		Use:               "start [ISSUE_NUMBER]",
 		Short:             "start working on an open issue",
-		Args:              cobra.ExactArgs(1),
+		Args:              cobra.MaximumNArgs(1),
+		Annotations:       map[string]string{permissionsAnnotation: "repo,project:write"},
 		RunE:              runStart,
 		ValidArgsFunction: completeOpenIssuesForProject,
 	}
 )
 
-func prepareCheckout(cmd *cobra.Command) (err error) {
+// prepareCheckout brings the default branch up to date, stashing uncommitted changes first if asked to.
+func prepareCheckout(cmd *cobra.Command, issueNumber int) (defaultBranch string, stashed bool, err error) {
 	// status
 	cmd.Println("Checking status of current branch...")
-	statusCmd := exec.Command("git", "status")
-	out, err := statusCmd.Output()
-	cmd.Println(string(out))
+	status, err := git.GetStatus("")
 	if err != nil {
 		return
 	}
-	r := regexp.MustCompile("nothing to commit")
-	match := r.Match(out)
-	if !match {
-		err = errors.New("there might be unsaved changes in the current repository.\nResolve them before creating a new branch")
+	if len(status.Conflicted) != 0 {
+		err = fmt.Errorf("there are unresolved conflicts in the current repository:\n%s\nResolve them before creating a new branch", status)
 		return
 	}
+	if !status.Clean() {
+		if !startStash && !startCarry {
+			err = fmt.Errorf("%w:\n%s\nCommit them, or use --stash or --carry before creating a new branch", git.ErrDirtyTree, status)
+			return
+		}
+		cmd.Println("Stashing uncommitted changes...")
+		message := fmt.Sprintf("git-matsuri: before starting %s", matsuri.BranchName(issueNumber))
+		if _, err = git.Run("", "stash", "push", "--include-untracked", "--message", message); err != nil {
+			return
+		}
+		stashed = true
+	}
 
 	// checkout default
-	cmd.Println("Checking out default branch...")
-	defaultBranch, _ := matsuri.GetDefaultBranch()
-	checkoutCmd := exec.Command("git", "checkout", *defaultBranch)
-	out, err = checkoutCmd.Output()
-	cmd.Println(string(out))
+	branch, err := matsuri.GetBaseBranch()
+	if err != nil {
+		return
+	}
+	defaultBranch = *branch
+	cmd.Printf("Checking out %s...\n", defaultBranch)
+	out, err := git.Run("", "checkout", defaultBranch)
 	if err != nil {
 		return
 	}
+	cmd.Print(out)
 
 	// pull
 	cmd.Println("Pulling changes...")
-	pullCmd := exec.Command("git", "pull")
-	out, err = pullCmd.Output()
-	cmd.Println(string(out))
+	out, err = git.Run("", "pull")
+	if err != nil {
+		return
+	}
+	cmd.Print(out)
 	return
 }
 
 func runStart(cmd *cobra.Command, args []string) (err error) {
-	issueNumber, err := strconv.Atoi(args[0])
+	var issueNumber int
+	if len(args) == 0 {
+		issueNumber, err = pickIssue(cmd, matsuri.GetOpenIssuesForProject)
+	} else {
+		issueNumber, err = strconv.Atoi(args[0])
+	}
 	if err != nil {
 		return
 	}
@@ -65,26 +89,132 @@ func runStart(cmd *cobra.Command, args []string) (err error) {
 		err = errors.New("invalid Issue provided")
 		return
 	}
-	err = prepareCheckout(cmd)
+	login, err := checkAssignees(cmd, issueNumber)
+	if err != nil {
+		return
+	}
+	defaultBranch, stashed, err := prepareCheckout(cmd, issueNumber)
+	if stashed {
+		defer func() {
+			err = restoreStash(cmd, err)
+		}()
+	}
 	if err != nil {
 		return
 	}
-	// Some Issues may not be assigned to a Project, so we'll ignore errors here
-	_ = matsuri.MoveProjectCardForProject(issueNumber)
 	// checkout branch
 	cmd.Println("Checking out topic branch...")
-	branchName := fmt.Sprintf("ISSUE-%d", issueNumber)
-	checkoutCmd := exec.Command("git", "checkout", "-b", branchName)
-	out, err := checkoutCmd.Output()
+	branchName := matsuri.BranchName(issueNumber)
+	resumed, err := checkoutTopicBranch(cmd, branchName)
 	if err != nil {
-		err = fmt.Errorf("there was an issue creating the git branch: %s", err.Error())
+		err = fmt.Errorf("there was an issue checking out the git branch: %w", err)
 		return
 	}
-	cmd.Println(string(out))
+	// the issue is only taken once its branch is checked out
+	// Some Issues may not be assigned to a Project, so we'll ignore errors here
+	_ = matsuri.MoveProjectCardForProject(issueNumber)
+	cmd.Printf("Assigning the issue to @%s...\n", login)
+	if assignErr := matsuri.AssignIssue(issueNumber, login); assignErr != nil {
+		cmd.Printf("WARN: the issue could not be assigned to you: %s\n", matsuri.DescribeError(assignErr).Error())
+	}
+	if resumed && startRebase {
+		cmd.Printf("Rebasing onto %s...\n", defaultBranch)
+		var out string
+		if out, err = git.Run("", "rebase", defaultBranch); err != nil {
+			err = fmt.Errorf("%w\nResolve the conflicts and run `git rebase --continue`, or run `git rebase --abort`", err)
+			return
+		}
+		cmd.Print(out)
+	}
 	cmd.Printf("You are now working in branch %s\n", branchName)
 	return
 }
 
+// checkAssignees gets the login of the authenticated user, and makes sure that nobody else is working on the issue unless forced.
+func checkAssignees(cmd *cobra.Command, issueNumber int) (login string, err error) {
+	if login, err = matsuri.GetAuthenticatedUser(); err != nil {
+		return
+	}
+	assignees, err := matsuri.GetIssueAssignees(issueNumber)
+	if err != nil {
+		return
+	}
+	var others []string
+	for _, assignee := range assignees {
+		if !strings.EqualFold(assignee, login) {
+			others = append(others, "@"+assignee)
+		}
+	}
+	if len(others) == 0 {
+		return
+	}
+	if !startForce {
+		err = fmt.Errorf("%s is already assigned to %s, make sure they are not working on it and use --force to start it anyway",
+			matsuri.BranchName(issueNumber), strings.Join(others, ", "))
+		return
+	}
+	cmd.Printf("WARN: %s is already assigned to %s\n", matsuri.BranchName(issueNumber), strings.Join(others, ", "))
+	return
+}
+
+// checkoutTopicBranch checks out the topic branch, resuming it if it already exists locally or on the remote,
+// and creating it from the current branch otherwise.
+func checkoutTopicBranch(cmd *cobra.Command, branchName string) (resumed bool, err error) {
+	local, err := git.HasRef("", "refs/heads/"+branchName)
+	if err != nil {
+		return
+	}
+	remote, err := git.HasRef("", "refs/remotes/origin/"+branchName)
+	if err != nil {
+		return
+	}
+	var out string
+	switch {
+	case local:
+		cmd.Printf("Resuming existing branch %s...\n", branchName)
+		if out, err = git.Run("", "checkout", branchName); err != nil {
+			return
+		}
+		cmd.Print(out)
+		if remote {
+			if _, err = git.Run("", "branch", "--set-upstream-to", "origin/"+branchName); err != nil {
+				return
+			}
+			// bring in the commits pushed from elsewhere, leaving diverged branches to the user
+			if _, mergeErr := git.Run("", "merge", "--ff-only", "origin/"+branchName); mergeErr != nil {
+				cmd.Printf("WARN: %s and origin/%s have diverged, pull or rebase to reconcile them\n", branchName, branchName)
+			}
+		}
+	case remote:
+		cmd.Printf("Resuming branch %s from origin...\n", branchName)
+		out, err = git.Run("", "checkout", "-b", branchName, "--track", "origin/"+branchName)
+		cmd.Print(out)
+	default:
+		out, err = git.Run("", "checkout", "-b", branchName)
+		cmd.Print(out)
+	}
+	resumed = local || remote
+	return
+}
+
+// restoreStash brings back the changes stashed by prepareCheckout when carrying them, or tells the user where they are.
+func restoreStash(cmd *cobra.Command, err error) error {
+	if !startCarry || err != nil {
+		cmd.Println("Your uncommitted changes were stashed, run `git stash pop` to restore them")
+		return err
+	}
+	cmd.Println("Restoring uncommitted changes...")
+	if _, popErr := git.Run("", "stash", "pop", "--index"); popErr != nil {
+		return fmt.Errorf("your uncommitted changes could not be carried over and are still stashed: %w", popErr)
+	}
+	return nil
+}
+
 func init() {
+	startCmd.Flags().BoolVar(&startStash, "stash", false, "stashes uncommitted changes before starting")
+	startCmd.Flags().BoolVar(&startCarry, "carry", false, "carries uncommitted changes over to the new branch")
+	startCmd.Flags().BoolVar(&startRebase, "rebase", false, "rebases an existing topic branch onto the default branch")
+	startCmd.Flags().BoolVarP(&startForce, "force", "f", false, "starts the issue even if someone else is assigned to it")
+	startCmd.MarkFlagsMutuallyExclusive("stash", "carry")
 	rootCmd.AddCommand(startCmd)
 }
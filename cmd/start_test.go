@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/google/go-github/v29/github"
+)
+
+// newTestProject adds the board of the festival, with issue 1 of web in its to do column.
+func newTestProject(r *testRepo) *matsuri.ProjectV2 {
+	r.gh.AddIssue("web", "Fix the header")
+	project := r.gh.AddProjectV2("Matsuri 2024", "To do", "In progress", "Done")
+	r.gh.AddItem(project, "web", 1, "To do")
+	return project
+}
+
+// itemStatus gets the status of the item of an issue or pull request of web, or "" if it is not on the board.
+func itemStatus(r *testRepo, project *matsuri.ProjectV2, number int) string {
+	for _, item := range r.gh.Items(project) {
+		if item.Repository == "web" && item.Number == number {
+			return item.Status
+		}
+	}
+	return ""
+}
+
+// newTestClassicProject is newTestProject for classic projects, which it selects in the user configuration.
+func newTestClassicProject(r *testRepo) *github.Project {
+	r.userConfig("classic_projects: true\n")
+	r.gh.AddIssue("web", "Fix the header")
+	project := r.gh.AddProject("Matsuri 2024", "To do", "In progress", "Done")
+	r.gh.AddCard(r.gh.Column(project, "To do"), "web", 1)
+	return project
+}
+
+// cardColumn gets the column holding the card of an issue or pull request of web in a classic project, or "" if it is not there.
+func cardColumn(r *testRepo, project *github.Project, number int) string {
+	for _, name := range []string{"To do", "In progress", "Done"} {
+		for _, card := range r.gh.Cards(r.gh.Column(project, name)) {
+			if matsuri.GetRepoNameFromURL(card.GetContentURL()) == "web" && matsuri.GetIssueNumberFromCard(card) == number {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// closeIssue closes an issue of web, as if its pull request was merged.
+func closeIssue(t *testing.T, r *testRepo, number int) {
+	t.Helper()
+	if _, _, err := r.gh.Backend().Issues.Edit(context.Background(), "MatsuriJapon", "web", number, &github.IssueRequest{State: github.String("closed")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStart(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+
+	out := r.mustRun("start", "1")
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "ISSUE-1" {
+		t.Errorf("start checked out %q", branch)
+	}
+	if status := itemStatus(r, project, 1); status != "In progress" {
+		t.Errorf("the card is in %q", status)
+	}
+	if assignees := r.gh.Issue("web", 1).Assignees; len(assignees) != 1 || assignees[0].GetLogin() != "volunteer" {
+		t.Errorf("the issue is assigned to %v", assignees)
+	}
+	if !strings.Contains(out, "You are now working in branch ISSUE-1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestStartRefusesIssueOfSomeoneElse(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	if _, _, err := r.gh.Backend().Issues.AddAssignees(context.Background(), "MatsuriJapon", "web", 1, []string{"someone"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.run("start", "1"); err == nil || !strings.Contains(err.Error(), "already assigned to @someone") {
+		t.Fatalf("start error = %v", err)
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "master" {
+		t.Errorf("start checked out %q", branch)
+	}
+	if status := itemStatus(r, project, 1); status != "To do" {
+		t.Errorf("the card was moved to %q", status)
+	}
+
+	out := r.mustRun("start", "--force", "1")
+	if !strings.Contains(out, "WARN: ISSUE-1 is already assigned to @someone") {
+		t.Errorf("start --force did not warn:\n%s", out)
+	}
+	if status := itemStatus(r, project, 1); status != "In progress" {
+		t.Errorf("the card is in %q", status)
+	}
+}
+
+func TestStartCheckoutFailure(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	r.failGit(nil, "fatal: cannot lock ref 'refs/heads/ISSUE-1': Permission denied", "checkout", "-b")
+
+	if _, err := r.run("start", "1"); err == nil || !strings.Contains(err.Error(), "there was an issue checking out the git branch") {
+		t.Fatalf("start error = %v", err)
+	}
+	if status := itemStatus(r, project, 1); status != "To do" {
+		t.Errorf("the card was moved to %q", status)
+	}
+	if assignees := r.gh.Issue("web", 1).Assignees; len(assignees) != 0 {
+		t.Errorf("the issue is assigned to %v", assignees)
+	}
+}
+
+func TestStartWithUncommittedChanges(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	if err := os.WriteFile(filepath.Join(r.dir, "notes.txt"), []byte("draft\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.run("start", "1"); !errors.Is(err, git.ErrDirtyTree) {
+		t.Fatalf("start error = %v, want %v", err, git.ErrDirtyTree)
+	}
+
+	r.mustRun("start", "--carry", "1")
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "ISSUE-1" {
+		t.Errorf("start checked out %q", branch)
+	}
+	if _, err := os.Stat(filepath.Join(r.dir, "notes.txt")); err != nil {
+		t.Errorf("the uncommitted file was not carried over: %v", err)
+	}
+	if stashes := r.git(r.dir, "stash", "list"); stashes != "" {
+		t.Errorf("changes were left in the stash: %s", stashes)
+	}
+}
+
+func TestStartStashesUncommittedChanges(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	if err := os.WriteFile(filepath.Join(r.dir, "notes.txt"), []byte("draft\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := r.mustRun("start", "--stash", "1")
+	if !strings.Contains(out, "run `git stash pop` to restore them") {
+		t.Errorf("start did not say where the changes are:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(r.dir, "notes.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the uncommitted file is still in the working tree: %v", err)
+	}
+	if stashes := r.git(r.dir, "stash", "list"); !strings.Contains(stashes, "ISSUE-1") {
+		t.Errorf("the changes were not stashed: %q", stashes)
+	}
+}
+
+func TestStartResumesRemoteBranch(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.git(r.dir, "checkout", "--quiet", "-b", "ISSUE-1")
+	work := r.commit("header.html", "<h1>Matsuri</h1>\n")
+	r.git(r.dir, "push", "--quiet", "origin", "ISSUE-1")
+	r.git(r.dir, "checkout", "--quiet", "master")
+	r.git(r.dir, "branch", "--quiet", "-D", "ISSUE-1")
+
+	out := r.mustRun("start", "1")
+	if !strings.Contains(out, "Resuming branch ISSUE-1 from origin") {
+		t.Errorf("start did not resume the branch:\n%s", out)
+	}
+	if head := r.git(r.dir, "rev-parse", "HEAD"); head != work {
+		t.Errorf("start checked out %s, want the pushed commit %s", head, work)
+	}
+	if upstream := r.git(r.dir, "rev-parse", "--abbrev-ref", "@{upstream}"); upstream != "origin/ISSUE-1" {
+		t.Errorf("the branch tracks %q", upstream)
+	}
+}
+
+func TestStartRebasesLocalBranch(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.git(r.dir, "checkout", "--quiet", "-b", "ISSUE-1")
+	r.commit("header.html", "<h1>Matsuri</h1>\n")
+	r.git(r.dir, "checkout", "--quiet", "master")
+	base := r.commit("footer.html", "<footer></footer>\n")
+	r.git(r.dir, "push", "--quiet", "origin", "master")
+
+	out := r.mustRun("start", "--rebase", "1")
+	if !strings.Contains(out, "Resuming existing branch ISSUE-1") {
+		t.Errorf("start did not resume the branch:\n%s", out)
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "ISSUE-1" {
+		t.Errorf("start checked out %q", branch)
+	}
+	if parent := r.git(r.dir, "rev-parse", "HEAD~1"); parent != base {
+		t.Errorf("the branch was not rebased onto %s, its parent is %s", base, parent)
+	}
+}
+
+func TestStartClosedIssue(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	closeIssue(t, r, 1)
+	if _, err := r.run("start", "1"); err == nil {
+		t.Error("start accepted a closed issue")
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "master" {
+		t.Errorf("start checked out %q", branch)
+	}
+}
+
+func TestStartClassicProject(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestClassicProject(r)
+	r.mustRun("start", "1")
+	if column := cardColumn(r, project, 1); column != "In progress" {
+		t.Errorf("the card is in %q", column)
+	}
+}
+
+func TestStartWithRepositoryConfig(t *testing.T) {
+	r := newTestRepo(t)
+	r.gh.AddIssue("web", "Fix the header")
+	project := r.gh.AddProjectV2("Festival 2024", "Backlog", "Doing", "Done")
+	r.gh.AddItem(project, "web", 1, "Backlog")
+	r.commit(".matsuri.yml", "project_pattern: ^Festival\ncolumns:\n  todo: Backlog\n  in_progress: Doing\nbranch_format: web-%d\n")
+
+	r.mustRun("start", "1")
+	if status := itemStatus(r, project, 1); status != "Doing" {
+		t.Errorf("the card is in %q", status)
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "web-1" {
+		t.Errorf("start checked out %q", branch)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.userConfig("project_pattern: \"(\"\n")
+	if _, err := r.run("start", "1"); err == nil || !strings.Contains(err.Error(), "invalid project_pattern") {
+		t.Errorf("start error = %v", err)
+	}
+}
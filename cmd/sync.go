@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncMerge    bool
+	syncContinue bool
+	syncAbort    bool
+	syncCmd      = &cobra.Command{
+		Use:   "sync",
+		Short: "update the current topic branch with the latest default branch",
+		Long: "Fetch, then rebase the current topic branch onto the branch it started from, or merge that branch into it with --merge, " +
+			"and push the result. When there are conflicts, resolve them and run `git matsuri sync --continue`, " +
+			"or give up with `git matsuri sync --abort`.",
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{permissionsAnnotation: "repo"},
+		RunE:        runSync,
+	}
+)
+
+// conflictError lists the files to resolve when a rebase or merge stopped on conflicts, and returns err as is otherwise.
+func conflictError(operation string, err error) error {
+	status, statusErr := git.GetStatus("")
+	if statusErr != nil || len(status.Conflicted) == 0 {
+		return err
+	}
+	return fmt.Errorf("the %s stopped on conflicts in these files:\n  %s\n"+
+		"Resolve them and `git add` them, then run `git matsuri sync --continue`, or run `git matsuri sync --abort`",
+		operation, strings.Join(status.Conflicted, "\n  "))
+}
+
+// pullTopicBranch brings in the commits pushed to the topic branch from elsewhere, which the forced push would drop otherwise.
+func pullTopicBranch(cmd *cobra.Command, branch string) (err error) {
+	remote, err := git.HasRef("", "refs/remotes/origin/"+branch)
+	if err != nil || !remote {
+		return
+	}
+	missing, err := git.Output("", "rev-list", "--count", "HEAD..origin/"+branch)
+	if err != nil {
+		return
+	}
+	if n, _ := strconv.Atoi(missing); n == 0 {
+		return
+	}
+	cmd.Printf("Pulling the commits pushed to origin/%s...\n", branch)
+	if _, err = git.Run("", "merge", "--ff-only", "origin/"+branch); err != nil {
+		err = fmt.Errorf("%w: %s and origin/%s have diverged, reconcile them before syncing", git.ErrNonFastForward, branch, branch)
+	}
+	return
+}
+
+// checkTopicBranch makes sure that branch is a topic branch, which sync may push with --force-with-lease.
+func checkTopicBranch(branch string) error {
+	if branch == "" {
+		return errors.New("the branch being synced could not be found")
+	}
+	if _, ok := matsuri.IssueNumberFromBranch(branch); !ok {
+		return fmt.Errorf("the branch %q is not named like an issue branch (%s)", branch, strings.Join(matsuri.BranchPatterns(), ", "))
+	}
+	return nil
+}
+
+// pushSyncedBranch pushes the topic branch, overwriting the commits replaced by the rebase.
+func pushSyncedBranch(cmd *cobra.Command, branch string) (err error) {
+	cmd.Println("Pushing your changes to GitHub...")
+	// the lease refuses to overwrite commits pushed to GitHub since the last fetch
+	out, err := git.RunWithProgress(cmd.ErrOrStderr(), "", "push", "--force-with-lease", "-u", "origin", branch+":"+branch)
+	if errors.Is(err, git.ErrNonFastForward) {
+		return fmt.Errorf("%w\nThe branch was updated on GitHub in the meantime, run `git matsuri sync` again to include those commits", err)
+	}
+	if err != nil {
+		return fmt.Errorf("there was a problem pushing the branch: %w", err)
+	}
+	cmd.Print(out)
+	cmd.Printf("%s is in sync\n", branch)
+	return
+}
+
+func runSync(cmd *cobra.Command, args []string) (err error) {
+	operation, branch, err := git.InProgress("")
+	if err != nil {
+		return
+	}
+	switch {
+	case syncAbort:
+		if operation == "" {
+			return errors.New("there is no rebase or merge to abort")
+		}
+		cmd.Printf("Aborting the %s...\n", operation)
+		_, err = git.Run("", operation, "--abort")
+		return
+	case syncContinue:
+		if operation == "" {
+			return errors.New("there is no rebase or merge to continue")
+		}
+		if err = checkTopicBranch(branch); err != nil {
+			return fmt.Errorf("%w: continue the %s with `git %s --continue` and push the branch yourself", err, operation, operation)
+		}
+		cmd.Printf("Continuing the %s...\n", operation)
+		// keep the commit messages instead of opening an editor for each of them
+		if _, err = git.Default.Run(git.Command{Args: []string{operation, "--continue"}, Env: []string{"GIT_EDITOR=true"}}); err != nil {
+			return conflictError(operation, err)
+		}
+		return pushSyncedBranch(cmd, branch)
+	case operation != "":
+		return fmt.Errorf("a %s is in progress, run `git matsuri sync --continue` or `git matsuri sync --abort` first", operation)
+	}
+
+	if branch, err = git.CurrentBranch(""); err != nil {
+		return
+	}
+	if err = checkTopicBranch(branch); err != nil {
+		return
+	}
+	status, err := git.GetStatus("")
+	if err != nil {
+		return
+	}
+	if !status.Clean() {
+		return fmt.Errorf("%w:\n%s\nCommit or stash them before syncing", git.ErrDirtyTree, status)
+	}
+	base, err := matsuri.GetBaseBranch()
+	if err != nil {
+		return
+	}
+
+	cmd.Println("Fetching changes...")
+	if _, err = git.RunWithProgress(cmd.ErrOrStderr(), "", "fetch", "origin"); err != nil {
+		return
+	}
+	if err = pullTopicBranch(cmd, branch); err != nil {
+		return
+	}
+	upstream := "origin/" + *base
+	operation = git.OperationRebase
+	gitArgs := []string{"rebase", upstream}
+	if syncMerge {
+		operation = git.OperationMerge
+		gitArgs = []string{"merge", "--no-edit", upstream}
+		cmd.Printf("Merging %s into %s...\n", upstream, branch)
+	} else {
+		cmd.Printf("Rebasing %s onto %s...\n", branch, upstream)
+	}
+	out, err := git.Run("", gitArgs...)
+	if err != nil {
+		return conflictError(operation, err)
+	}
+	cmd.Print(out)
+	return pushSyncedBranch(cmd, branch)
+}
+
+func init() {
+	syncCmd.Flags().BoolVar(&syncMerge, "merge", false, "merges the default branch instead of rebasing")
+	syncCmd.Flags().BoolVar(&syncContinue, "continue", false, "continues after resolving conflicts")
+	syncCmd.Flags().BoolVar(&syncAbort, "abort", false, "gives up the rebase or merge stopped on conflicts")
+	syncCmd.MarkFlagsMutuallyExclusive("merge", "continue", "abort")
+	rootCmd.AddCommand(syncCmd)
+}
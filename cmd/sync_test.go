@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+)
+
+func TestSyncNonFastForward(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.mustRun("save")
+	r.failGit(git.ErrNonFastForward, " ! [rejected]        ISSUE-1 -> ISSUE-1 (stale info)", "push", "--force-with-lease")
+
+	_, err := r.run("sync")
+	if !errors.Is(err, git.ErrNonFastForward) || !strings.Contains(err.Error(), "run `git matsuri sync` again") {
+		t.Errorf("sync returned %v, want a non-fast-forward push", err)
+	}
+}
+
+func TestSync(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	r.mustRun("save")
+	// master moves on in the meantime
+	r.git(r.dir, "checkout", "--quiet", "master")
+	base := r.commit("footer.html", "<footer>\n")
+	r.git(r.dir, "push", "--quiet", "origin", "master")
+	r.git(r.dir, "checkout", "--quiet", "ISSUE-1")
+
+	out := r.mustRun("sync")
+	if !strings.Contains(out, "ISSUE-1 is in sync") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if parent := r.git(r.dir, "rev-parse", "origin/ISSUE-1^"); parent != base {
+		t.Errorf("ISSUE-1 was not rebased onto master, its parent is %s", parent)
+	}
+}
+
+func TestSyncRefusesNonTopicBranch(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.git(r.dir, "checkout", "--quiet", "-b", "redesign")
+	r.commit("header.html", "<header>\n")
+
+	_, err := r.run("sync")
+	if err == nil || !strings.Contains(err.Error(), `the branch "redesign" is not named like an issue branch`) {
+		t.Errorf("sync returned %v", err)
+	}
+	if r.hasRemoteBranch("redesign") {
+		t.Error("the branch was pushed")
+	}
+}
+
+func TestSyncContinueRefusesNonTopicBranch(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	// a rebase of another branch stops on conflicts
+	r.git(r.dir, "checkout", "--quiet", "-b", "redesign")
+	r.commit("README.md", "redesign\n")
+	r.git(r.dir, "checkout", "--quiet", "master")
+	r.commit("README.md", "master\n")
+	r.git(r.dir, "checkout", "--quiet", "redesign")
+	rebase := exec.Command("git", "rebase", "master")
+	rebase.Dir = r.dir
+	if out, err := rebase.CombinedOutput(); err == nil {
+		t.Fatalf("the rebase did not stop on conflicts:\n%s", out)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, "README.md"), []byte("both\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r.git(r.dir, "add", "README.md")
+
+	_, err := r.run("sync", "--continue")
+	if err == nil || !strings.Contains(err.Error(), `the branch "redesign" is not named like an issue branch`) ||
+		!strings.Contains(err.Error(), "continue the rebase with `git rebase --continue`") {
+		t.Errorf("sync --continue returned %v", err)
+	}
+	if operation, _, _ := git.InProgress(r.dir); operation != git.OperationRebase {
+		t.Errorf("the rebase was continued, %q is in progress", operation)
+	}
+	if r.hasRemoteBranch("redesign") {
+		t.Error("the branch was pushed")
+	}
+}
@@ -7,24 +7,73 @@ import (
 
 var (
 	todoCmd = &cobra.Command{
-		Use:   "todo",
-		Short: "list opened issues",
-		RunE:  runTodo,
+		Use:         "todo [YEAR]",
+		Short:       "list opened issues",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{permissionsAnnotation: "repo,project:read"},
+		RunE:        runTodo,
 	}
 	showOnlyCurrentRepo bool
+	todoLimit           int
+	todoFilter          matsuri.IssueFilter
+	todoMine            bool
+	todoGroupBy         string
+	todoSort            string
 )
 
 func runTodo(cmd *cobra.Command, args []string) error {
-	issues, err := matsuri.GetOpenIssues(showOnlyCurrentRepo)
-	if err != nil {
+	if err := yearArg(cmd, args); err != nil {
 		return err
 	}
-
-	matsuri.PrintIssues(issues)
-	return nil
+	if err := matsuri.ValidateGroupBy(todoGroupBy); err != nil {
+		return err
+	}
+	if err := matsuri.ValidateSort(todoSort); err != nil {
+		return err
+	}
+	filter := todoFilter
+	if todoMine {
+		login, err := matsuri.GetAuthenticatedUser()
+		if err != nil {
+			return err
+		}
+		filter.Assignee = login
+	}
+	// the limit applies after filtering and sorting, which need every issue
+	limit := todoLimit
+	if !filter.Empty() || todoSort != "" {
+		limit = 0
+	}
+	issues, err := matsuri.GetOpenIssues(showOnlyCurrentRepo, filter.Repo, limit)
+	issues = matsuri.FilterIssues(issues, &filter)
+	_ = matsuri.SortIssues(issues, todoSort)
+	if todoLimit > 0 && len(issues) > todoLimit {
+		issues = issues[:todoLimit]
+	}
+	column := ""
+	if !showOnlyCurrentRepo {
+		column = matsuri.GetConfig().Columns.Todo
+	}
+	// issues that could be fetched are still printed before reporting the ones that could not
+	if printErr := matsuri.PrintIssues(issues, column, outputFormat, todoGroupBy); printErr != nil {
+		return printErr
+	}
+	return err
 }
 
 func init() {
 	todoCmd.Flags().BoolVarP(&showOnlyCurrentRepo, "current", "c", false, "show only issues for the current repo")
+	todoCmd.Flags().IntVar(&todoLimit, "limit", 0, "show at most this many issues (0 shows all)")
+	todoCmd.Flags().StringSliceVarP(&todoFilter.Labels, "label", "l", nil, "show only issues with all of these labels")
+	todoCmd.Flags().StringVarP(&todoFilter.Assignee, "assignee", "a", "", "show only issues assigned to this user")
+	todoCmd.Flags().BoolVar(&todoMine, "mine", false, "show only issues assigned to you")
+	todoCmd.Flags().BoolVar(&todoFilter.Unassigned, "unassigned", false, "show only issues nobody is assigned to")
+	todoCmd.Flags().StringVarP(&todoFilter.Milestone, "milestone", "m", "", "show only issues of this milestone")
+	todoCmd.Flags().StringVarP(&todoFilter.Repo, "repo", "r", "", "show only issues of this repository instead of the current one")
+	todoCmd.Flags().StringVarP(&todoFilter.Title, "search", "s", "", "show only issues whose title contains this text")
+	todoCmd.Flags().StringVar(&todoGroupBy, "group-by", "", "group issues by repo, label or assignee in the text and markdown output")
+	todoCmd.Flags().StringVar(&todoSort, "sort", "", "sort issues by created, updated (newest first) or number")
+	todoCmd.MarkFlagsMutuallyExclusive("assignee", "mine", "unassigned")
+	todoCmd.MarkFlagsMutuallyExclusive("current", "repo")
 	rootCmd.AddCommand(todoCmd)
 }
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTodoCurrentLimit(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	r.gh.AddIssue("web", "Fix the footer")
+	r.mustRun("start", "1")
+	r.commit("header.html", "<header>\n")
+	// GitHub lists the pull request first, as the newest issue of the repository
+	r.mustRun("pr")
+
+	out := captureStdout(t, func() { r.mustRun("todo", "--current", "--limit", "1") })
+	if out != "2 [web]: Fix the footer\n" {
+		t.Errorf("todo did not show the newest issue only:\n%s", out)
+	}
+}
+
+func TestTodoInvalidOptions(t *testing.T) {
+	r := newTestRepo(t)
+	newTestProject(r)
+	for _, args := range [][]string{{"todo", "--sort", "title"}, {"todo", "--group-by", "milestone"}} {
+		if _, err := r.run(args...); err == nil || !strings.HasPrefix(err.Error(), "invalid") {
+			t.Errorf("%v returned %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unassignCmd = &cobra.Command{
+		Use:     "unassign [ISSUE_NUMBER]",
+		Aliases: []string{"drop"},
+		Short:   "stop working on an issue and give it back",
+		Long: "Remove you from the assignees of the issue, along with the in progress label if the repository has one, " +
+			"and move its card back to the To do column so that someone else can start it. The topic branch is left as is.",
+		Args:              cobra.MaximumNArgs(1),
+		Annotations:       map[string]string{permissionsAnnotation: "repo,project:write"},
+		RunE:              runUnassign,
+		ValidArgsFunction: completeInProgressIssuesForProject,
+	}
+)
+
+func runUnassign(cmd *cobra.Command, args []string) (err error) {
+	issue, _, err := resolveIssue(cmd, args)
+	if err != nil {
+		return
+	}
+	if !matsuri.IsExistingIssue(issue) {
+		err = errors.New("the provided Issue doesn't exist")
+		return
+	}
+	login, err := matsuri.GetAuthenticatedUser()
+	if err != nil {
+		return
+	}
+	cmd.Printf("Unassigning @%s from the issue...\n", login)
+	if err = matsuri.UnassignIssue(issue, login); err != nil {
+		return
+	}
+	columns := matsuri.GetConfig().Columns
+	cmd.Printf("Moving the card back to %s...\n", columns.Todo)
+	// Some Issues may not be assigned to a Project, or were already moved
+	if moveErr := matsuri.MoveProjectCardBackToTodo(issue); moveErr != nil {
+		cmd.Printf("WARN: the card was not moved: %s\n", matsuri.DescribeError(moveErr).Error())
+	}
+	cmd.Printf("%s is no longer assigned to you\n", matsuri.BranchName(issue))
+	return
+}
+
+func init() {
+	rootCmd.AddCommand(unassignCmd)
+}
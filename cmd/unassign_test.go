@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnassign(t *testing.T) {
+	r := newTestRepo(t)
+	project := newTestProject(r)
+	r.mustRun("start", "1")
+
+	out := r.mustRun("unassign")
+	if !strings.Contains(out, "ISSUE-1 is no longer assigned to you") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if assignees := r.gh.Issue("web", 1).Assignees; len(assignees) != 0 {
+		t.Errorf("the issue is assigned to %v", assignees)
+	}
+	if status := itemStatus(r, project, 1); status != "To do" {
+		t.Errorf("the card is in %q", status)
+	}
+	if branch := r.git(r.dir, "branch", "--show-current"); branch != "ISSUE-1" {
+		t.Errorf("unassign checked out %q", branch)
+	}
+}
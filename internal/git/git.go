@@ -0,0 +1,217 @@
+// Package git runs git commands, capturing their output and turning their failures into typed errors.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrNotRepository is returned when git runs outside of a repository.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrDirtyTree is returned when uncommitted changes prevent an operation.
+	ErrDirtyTree = errors.New("there are uncommitted changes in the working tree")
+	// ErrNonFastForward is returned when the local and remote branches have diverged.
+	ErrNonFastForward = errors.New("the local and remote branches have diverged")
+	// ErrAuthentication is returned when the remote refuses the credentials, or the repository is not accessible.
+	ErrAuthentication = errors.New("authentication to the remote failed")
+)
+
+// kinds maps fragments of git error messages to the typed errors. Git runs with LC_ALL=C so that they can be recognized.
+var kinds = []struct {
+	fragments []string
+	kind      error
+}{
+	{[]string{"not a git repository"}, ErrNotRepository},
+	{[]string{"would be overwritten by", "commit your changes or stash them", "you have unstaged changes", "your index contains uncommitted changes"}, ErrDirtyTree},
+	{[]string{"non-fast-forward", "[rejected]", "fetch first", "not possible to fast-forward", "need to specify how to reconcile divergent branches"}, ErrNonFastForward},
+	{[]string{"authentication failed", "permission denied", "could not read username", "could not read from remote repository", "repository not found"}, ErrAuthentication},
+}
+
+// Error is returned when git exits with an error. It matches one of the typed errors with errors.Is when it could be recognized.
+type Error struct {
+	Args   []string
+	Stderr string
+	// Kind is the typed error matching Stderr, if any.
+	Kind error
+	Err  error
+}
+
+func newError(args []string, stderr string, err error) *Error {
+	e := &Error{Args: args, Stderr: strings.TrimSpace(stderr), Err: err}
+	lower := strings.ToLower(e.Stderr)
+	for _, k := range kinds {
+		for _, fragment := range k.fragments {
+			if strings.Contains(lower, fragment) {
+				e.Kind = k.kind
+				return e
+			}
+		}
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	message := e.Stderr
+	if message == "" {
+		message = e.Err.Error()
+	}
+	return fmt.Sprintf("git %s: %s", strings.Join(e.Args, " "), message)
+}
+
+// Unwrap returns the typed error, so that errors.Is(err, ErrDirtyTree) and the like work.
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Command is a git invocation.
+type Command struct {
+	// Dir is the working directory, the current one if empty.
+	Dir  string
+	Args []string
+	// Stdin is written to the standard input of git.
+	Stdin string
+	// Env holds additional environment variables.
+	Env []string
+	// Progress, if set, also receives the standard error of git as it runs, for long operations such as clone.
+	Progress io.Writer
+	// Interactive runs git attached to the terminal, for commands opening an editor. Its output is not captured.
+	Interactive bool
+}
+
+// Runner runs git commands and returns their standard output.
+type Runner interface {
+	Run(c Command) (stdout string, err error)
+}
+
+// RunnerFunc turns a function into a Runner, which makes it easy to fake git in tests.
+type RunnerFunc func(c Command) (string, error)
+
+// Run calls f.
+func (f RunnerFunc) Run(c Command) (string, error) {
+	return f(c)
+}
+
+// ExecRunner runs the git executable.
+type ExecRunner struct{}
+
+// Run runs git, failing with an *Error holding its standard error.
+func (ExecRunner) Run(c Command) (string, error) {
+	cmd := exec.Command("git", c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = append(append(os.Environ(), "LC_ALL=C"), c.Env...)
+	if c.Stdin != "" {
+		cmd.Stdin = strings.NewReader(c.Stdin)
+	}
+	if c.Interactive {
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return "", newError(c.Args, "", err)
+		}
+		return "", nil
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if c.Progress != nil {
+		cmd.Stderr = io.MultiWriter(&stderr, c.Progress)
+	}
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), newError(c.Args, stderr.String(), err)
+	}
+	return stdout.String(), nil
+}
+
+// Default is the Runner used by the functions of this package. Tests can replace it with a fake.
+var Default Runner = ExecRunner{}
+
+// Run runs git with the given arguments in dir, the current directory if empty.
+func Run(dir string, args ...string) (string, error) {
+	return Default.Run(Command{Dir: dir, Args: args})
+}
+
+// RunWithProgress runs git like Run, also copying its standard error to progress as it runs.
+func RunWithProgress(progress io.Writer, dir string, args ...string) (string, error) {
+	return Default.Run(Command{Dir: dir, Args: args, Progress: progress})
+}
+
+// Output runs git like Run and trims the output, for commands printing a single value.
+func Output(dir string, args ...string) (string, error) {
+	out, err := Run(dir, args...)
+	return strings.TrimSpace(out), err
+}
+
+// TopLevel gets the root directory of the repository containing dir.
+func TopLevel(dir string) (string, error) {
+	return Output(dir, "rev-parse", "--show-toplevel")
+}
+
+// RemoteURL gets the URL of a remote.
+func RemoteURL(dir, remote string) (string, error) {
+	return Output(dir, "config", "--get", "remote."+remote+".url")
+}
+
+// CurrentBranch gets the name of the checked out branch, or an empty string when HEAD is detached.
+func CurrentBranch(dir string) (string, error) {
+	branch, err := Output(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.Stderr == "" {
+		// symbolic-ref fails silently on a detached HEAD
+		return "", nil
+	}
+	return branch, err
+}
+
+// HasRef reports whether a reference, such as refs/heads/main or refs/remotes/origin/main, exists.
+func HasRef(dir, ref string) (bool, error) {
+	_, err := Run(dir, "rev-parse", "--verify", "--quiet", ref)
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.Stderr == "" {
+		// rev-parse --quiet fails silently on a missing reference
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Operations that conflicts can leave in progress, as returned by InProgress.
+const (
+	OperationRebase = "rebase"
+	OperationMerge  = "merge"
+)
+
+// InProgress gets the rebase or merge stopped in the repository containing dir, if any, along with the branch it is updating.
+func InProgress(dir string) (operation, branch string, err error) {
+	for _, o := range []struct{ path, operation string }{
+		{"rebase-merge", OperationRebase},
+		{"rebase-apply", OperationRebase},
+		{"MERGE_HEAD", OperationMerge},
+	} {
+		var path string
+		if path, err = Output(dir, "rev-parse", "--git-path", o.path); err != nil {
+			return
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, statErr := os.Stat(path); statErr != nil {
+			continue
+		}
+		if o.operation == OperationMerge {
+			branch, err = CurrentBranch(dir)
+			return o.operation, branch, err
+		}
+		// HEAD is detached while rebasing, the branch being rebased is recorded with it
+		head, readErr := os.ReadFile(filepath.Join(path, "head-name")) // #nosec
+		if readErr == nil {
+			branch = strings.TrimPrefix(strings.TrimSpace(string(head)), "refs/heads/")
+		}
+		return o.operation, branch, nil
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   error
+	}{
+		{"fatal: not a git repository (or any of the parent directories): .git", ErrNotRepository},
+		{"error: Your local changes to the following files would be overwritten by checkout:\n\tindex.html", ErrDirtyTree},
+		{"error: cannot rebase: You have unstaged changes.", ErrDirtyTree},
+		{" ! [rejected]        ISSUE-1 -> ISSUE-1 (non-fast-forward)", ErrNonFastForward},
+		{"fatal: Not possible to fast-forward, aborting.", ErrNonFastForward},
+		{"remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/MatsuriJapon/web.git/'", ErrAuthentication},
+		{"git@github.com: Permission denied (publickey).\nfatal: Could not read from remote repository.", ErrAuthentication},
+		{"ERROR: Repository not found.", ErrAuthentication},
+		{"fatal: ambiguous argument 'nope': unknown revision or path not in the working tree.", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		err := newError([]string{"push"}, tt.stderr, errors.New("exit status 128"))
+		if err.Kind != tt.want {
+			t.Errorf("newError(%q) is a %v, want %v", tt.stderr, err.Kind, tt.want)
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("errors.Is(newError(%q), %v) = false", tt.stderr, tt.want)
+		}
+	}
+}
+
+// fakeGit replaces Default with run for the rest of the test.
+func fakeGit(t *testing.T, run RunnerFunc) {
+	t.Helper()
+	previous := Default
+	Default = run
+	t.Cleanup(func() { Default = previous })
+}
+
+func TestRunUsesDefault(t *testing.T) {
+	var got Command
+	fakeGit(t, func(c Command) (string, error) {
+		got = c
+		return "  main\n", nil
+	})
+	out, err := Output("web", "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil || out != "main" {
+		t.Errorf("Output() = %q, %v, want main", out, err)
+	}
+	if want := (Command{Dir: "web", Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ran %+v, want %+v", got, want)
+	}
+}
+
+func TestSilentFailures(t *testing.T) {
+	silent := &Error{Err: errors.New("exit status 1")}
+	fakeGit(t, func(c Command) (string, error) {
+		return "", silent
+	})
+	if branch, err := CurrentBranch(""); branch != "" || err != nil {
+		t.Errorf("CurrentBranch() = %q, %v, want a detached HEAD", branch, err)
+	}
+	if ok, err := HasRef("", "refs/heads/ISSUE-1"); ok || err != nil {
+		t.Errorf("HasRef() = %v, %v, want a missing reference", ok, err)
+	}
+
+	// other failures are reported
+	fakeGit(t, func(c Command) (string, error) {
+		return "", newError(c.Args, "fatal: not a git repository (or any of the parent directories): .git", errors.New("exit status 128"))
+	})
+	if _, err := CurrentBranch(""); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("CurrentBranch() returned %v, want %v", err, ErrNotRepository)
+	}
+	if _, err := HasRef("", "refs/heads/ISSUE-1"); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("HasRef() returned %v, want %v", err, ErrNotRepository)
+	}
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Status is the state of a working tree, as reported by `git status --porcelain=v2 --branch`.
+type Status struct {
+	// Branch is the checked out branch, empty when HEAD is detached.
+	Branch string
+	// Upstream is the branch tracked by Branch, if any.
+	Upstream string
+	// Ahead and Behind count the commits that differ from Upstream.
+	Ahead, Behind int
+
+	Staged     []string
+	Unstaged   []string
+	Untracked  []string
+	Conflicted []string
+}
+
+// Clean reports whether the working tree has no changes at all, untracked files included.
+func (s *Status) Clean() bool {
+	return len(s.Staged)+len(s.Unstaged)+len(s.Untracked)+len(s.Conflicted) == 0
+}
+
+// String lists the changed files by kind, one per line.
+func (s *Status) String() string {
+	var b strings.Builder
+	for _, group := range []struct {
+		name  string
+		files []string
+	}{
+		{"conflicted", s.Conflicted},
+		{"staged", s.Staged},
+		{"unstaged", s.Unstaged},
+		{"untracked", s.Untracked},
+	} {
+		for _, file := range group.files {
+			fmt.Fprintf(&b, "  %-10s %s\n", group.name+":", file)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// GetStatus gets the status of the working tree containing dir. It does not depend on the language of git.
+func GetStatus(dir string) (status *Status, err error) {
+	out, err := Run(dir, "status", "--porcelain=v2", "--branch", "-z")
+	if err != nil {
+		return
+	}
+	return parseStatus(out)
+}
+
+// parseStatus parses the NUL separated output of `git status --porcelain=v2 --branch -z`.
+func parseStatus(out string) (status *Status, err error) {
+	status = &Status{}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if entry == "" {
+			continue
+		}
+		switch entry[0] {
+		case '#':
+			fields := strings.Fields(entry)
+			if len(fields) < 3 {
+				continue
+			}
+			switch fields[1] {
+			case "branch.head":
+				if fields[2] != "(detached)" {
+					status.Branch = fields[2]
+				}
+			case "branch.upstream":
+				status.Upstream = fields[2]
+			case "branch.ab":
+				if len(fields) == 4 {
+					status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[2], "+"))
+					status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[3], "-"))
+				}
+			}
+		case '1', '2':
+			// ordinary and renamed or copied entries: "1 XY sub mH mI mW hH hI path" and "2 XY sub mH mI mW hH hI Xscore path"
+			fieldCount := 9
+			if entry[0] == '2' {
+				fieldCount = 10
+				// the original path of a rename is the next entry
+				i++
+			}
+			fields := strings.SplitN(entry, " ", fieldCount)
+			if len(fields) != fieldCount || len(fields[1]) != 2 {
+				err = fmt.Errorf("unexpected git status entry %q", entry)
+				return
+			}
+			path := fields[fieldCount-1]
+			if fields[1][0] != '.' {
+				status.Staged = append(status.Staged, path)
+			}
+			if fields[1][1] != '.' {
+				status.Unstaged = append(status.Unstaged, path)
+			}
+		case 'u':
+			// unmerged entries: "u XY sub m1 m2 m3 mW h1 h2 h3 path"
+			fields := strings.SplitN(entry, " ", 11)
+			if len(fields) != 11 {
+				err = fmt.Errorf("unexpected git status entry %q", entry)
+				return
+			}
+			status.Conflicted = append(status.Conflicted, fields[10])
+		case '?':
+			status.Untracked = append(status.Untracked, strings.TrimPrefix(entry, "? "))
+		}
+	}
+	return
+}
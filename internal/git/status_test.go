@@ -0,0 +1,109 @@
+package git
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	const hashes = "100644 100644 100644 0123456789abcdef0123456789abcdef01234567 0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name    string
+		entries []string
+		want    *Status
+		wantErr bool
+	}{
+		{
+			name:    "clean branch without upstream",
+			entries: []string{"# branch.oid 0123456789abcdef0123456789abcdef01234567", "# branch.head ISSUE-12"},
+			want:    &Status{Branch: "ISSUE-12"},
+		},
+		{
+			name:    "detached head",
+			entries: []string{"# branch.oid 0123456789abcdef0123456789abcdef01234567", "# branch.head (detached)"},
+			want:    &Status{},
+		},
+		{
+			name: "upstream ahead and behind",
+			entries: []string{
+				"# branch.head master",
+				"# branch.upstream origin/master",
+				"# branch.ab +2 -3",
+			},
+			want: &Status{Branch: "master", Upstream: "origin/master", Ahead: 2, Behind: 3},
+		},
+		{
+			name: "staged, unstaged and untracked files",
+			entries: []string{
+				"# branch.head master",
+				"1 M. N... " + hashes + " staged.go",
+				"1 .M N... " + hashes + " unstaged.go",
+				"1 MM N... " + hashes + " both.go",
+				"? new file.txt",
+			},
+			want: &Status{
+				Branch:    "master",
+				Staged:    []string{"staged.go", "both.go"},
+				Unstaged:  []string{"unstaged.go", "both.go"},
+				Untracked: []string{"new file.txt"},
+			},
+		},
+		{
+			name: "rename keeps the new path and skips the original one",
+			entries: []string{
+				"# branch.head master",
+				"2 R. N... " + hashes + " R100 new name.go",
+				"old name.go",
+				"? after.txt",
+			},
+			want: &Status{Branch: "master", Staged: []string{"new name.go"}, Untracked: []string{"after.txt"}},
+		},
+		{
+			name: "conflicts",
+			entries: []string{
+				"# branch.head ISSUE-3",
+				"u UU N... 100644 100644 100644 100644 0123456789abcdef0123456789abcdef01234567 0123456789abcdef0123456789abcdef01234567 0123456789abcdef0123456789abcdef01234567 conflict.go",
+			},
+			want: &Status{Branch: "ISSUE-3", Conflicted: []string{"conflict.go"}},
+		},
+		{
+			name:    "truncated entry",
+			entries: []string{"1 M. N..."},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseStatus(strings.Join(tt.entries, "\x00") + "\x00")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseStatus() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseStatus() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusClean(t *testing.T) {
+	tests := []struct {
+		status *Status
+		want   bool
+	}{
+		{&Status{Branch: "master", Ahead: 1}, true},
+		{&Status{Untracked: []string{"a"}}, false},
+		{&Status{Conflicted: []string{"a"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.status.Clean(); got != tt.want {
+			t.Errorf("%+v.Clean() = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package picker lets the user choose an item from a list, with fuzzy filtering in a terminal
+// and a numbered prompt otherwise.
+package picker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/terminal"
+)
+
+// ErrCancelled is returned when the user leaves the picker without choosing.
+var ErrCancelled = errors.New("cancelled")
+
+// Item is an entry of the picker.
+type Item struct {
+	// Label is the line shown in the list, which the filter matches.
+	Label string
+	// Preview is shown below the list for the selected item.
+	Preview string
+}
+
+// Pick asks the user to choose one of items and returns its index. The fuzzy finder is used when in and out
+// are both terminals, and a numbered prompt read line by line otherwise.
+func Pick(in io.Reader, out io.Writer, prompt string, items []Item) (index int, err error) {
+	if len(items) == 0 {
+		return -1, errors.New("there is nothing to choose from")
+	}
+	if terminal.IsTerminal(in, out) {
+		return pickInteractive(in.(*os.File), out.(*os.File), prompt, items)
+	}
+	return pickNumbered(in, out, prompt, items)
+}
+
+// pickNumbered lists the items with a number and reads the number of the chosen one.
+func pickNumbered(in io.Reader, out io.Writer, prompt string, items []Item) (index int, err error) {
+	for i, item := range items {
+		fmt.Fprintf(out, "%3d) %s\n", i+1, item.Label)
+	}
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(out, "%s [1-%d]: ", prompt, len(items))
+		line, readErr := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if n, convErr := strconv.Atoi(line); convErr == nil && n >= 1 && n <= len(items) {
+			return n - 1, nil
+		}
+		if readErr != nil {
+			fmt.Fprintln(out)
+			return -1, ErrCancelled
+		}
+		if line != "" {
+			fmt.Fprintf(out, "%q is not a number between 1 and %d\n", line, len(items))
+		}
+	}
+}
+
+// Match scores how well query fuzzy matches text: every rune of query must appear in text in order, ignoring case.
+// Consecutive runes and runes starting a word score higher.
+func Match(query, text string) (score int, ok bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	t := []rune(strings.ToLower(text))
+	matched, last := 0, -2
+	for i, r := range t {
+		if matched == len(q) {
+			break
+		}
+		if r != q[matched] {
+			continue
+		}
+		score++
+		if i == last+1 {
+			score += 5
+		}
+		if i == 0 || !unicode.IsLetter(t[i-1]) && !unicode.IsDigit(t[i-1]) {
+			score += 3
+		}
+		last = i
+		matched++
+	}
+	return score, matched == len(q)
+}
+
+// Filter returns the indexes of the items matching query, best matches first.
+func Filter(items []Item, query string) (indexes []int) {
+	scores := map[int]int{}
+	for i, item := range items {
+		if score, ok := Match(query, item.Label); ok {
+			indexes = append(indexes, i)
+			scores[i] = score
+		}
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return scores[indexes[a]] > scores[indexes[b]]
+	})
+	return
+}
@@ -0,0 +1,93 @@
+package picker
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query, text string
+		wantScore   int
+		wantOK      bool
+	}{
+		{query: "", text: "anything", wantScore: 0, wantOK: true},
+		{query: "abc", text: "abc", wantScore: 1 + 3 + 1 + 5 + 1 + 5, wantOK: true},
+		{query: "ABC", text: "abc", wantScore: 1 + 3 + 1 + 5 + 1 + 5, wantOK: true},
+		{query: "ac", text: "abc", wantScore: 3 + 1 + 1, wantOK: true},
+		{query: "fh", text: "fix header", wantScore: 1 + 3 + 1 + 3, wantOK: true},
+		{query: "ca", text: "abc", wantOK: false},
+		{query: "abcd", text: "abc", wantOK: false},
+		{query: "12", text: "#12 [web]", wantScore: 1 + 3 + 1 + 5, wantOK: true},
+		{query: "é", text: "Café", wantScore: 1, wantOK: true},
+	}
+	for _, tt := range tests {
+		score, ok := Match(tt.query, tt.text)
+		if ok != tt.wantOK || ok && score != tt.wantScore {
+			t.Errorf("Match(%q, %q) = %d, %v, want %d, %v", tt.query, tt.text, score, ok, tt.wantScore, tt.wantOK)
+		}
+	}
+}
+
+func TestMatchPrefersConsecutiveAndWordStarts(t *testing.T) {
+	better, _ := Match("head", "header")
+	worse, _ := Match("head", "h-e-a-d")
+	if better <= worse {
+		t.Errorf("consecutive match scored %d, scattered match %d", better, worse)
+	}
+	start, _ := Match("h", "my-header")
+	inside, _ := Match("h", "other")
+	if start <= inside {
+		t.Errorf("word start scored %d, match inside a word %d", start, inside)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	items := []Item{{Label: "12 [web]: Fix the header"}, {Label: "3 [api]: Add a footer"}, {Label: "7 [web]: Header links"}}
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{query: "", want: []int{0, 1, 2}},
+		{query: "header", want: []int{2, 0}},
+		{query: "web", want: []int{0, 2}},
+		{query: "zzz"},
+	}
+	for _, tt := range tests {
+		if got := Filter(items, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Filter(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPickNumbered(t *testing.T) {
+	items := []Item{{Label: "one"}, {Label: "two"}, {Label: "three"}}
+	tests := []struct {
+		name      string
+		input     string
+		wantIndex int
+		wantErr   error
+	}{
+		{name: "number", input: "2\n", wantIndex: 1},
+		{name: "retries after invalid input", input: "x\n\n9\n3\n", wantIndex: 2},
+		{name: "last line without newline", input: "1", wantIndex: 0},
+		{name: "end of input", input: "x\n", wantIndex: -1, wantErr: ErrCancelled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			index, err := Pick(strings.NewReader(tt.input), &out, "Issue", items)
+			if index != tt.wantIndex || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Pick() = %d, %v, want %d, %v", index, err, tt.wantIndex, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), "  2) two\n") {
+				t.Errorf("Pick() did not list the items:\n%s", out.String())
+			}
+		})
+	}
+	if _, err := Pick(strings.NewReader("1\n"), &strings.Builder{}, "Issue", nil); err == nil {
+		t.Error("Pick() accepted an empty list")
+	}
+}
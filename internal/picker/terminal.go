@@ -0,0 +1,133 @@
+package picker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/terminal"
+)
+
+// finder is the state of the interactive fuzzy finder.
+type finder struct {
+	prompt  string
+	items   []Item
+	query   []rune
+	matches []int
+	// cursor is the position of the selected item in matches, and offset the first one shown.
+	cursor, offset int
+}
+
+func (f *finder) filter() {
+	f.matches = Filter(f.items, string(f.query))
+	f.cursor, f.offset = 0, 0
+}
+
+func (f *finder) move(delta int) {
+	f.cursor += delta
+	if f.cursor >= len(f.matches) {
+		f.cursor = len(f.matches) - 1
+	}
+	if f.cursor < 0 {
+		f.cursor = 0
+	}
+}
+
+// render draws the finder on the whole screen, the list at the top and the preview below.
+func (f *finder) render(t *terminal.Terminal) {
+	width, height := t.Size()
+	var b strings.Builder
+
+	listHeight := (height - 3) / 2
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+listHeight {
+		f.offset = f.cursor - listHeight + 1
+	}
+	fmt.Fprintf(&b, "\x1b[2m%d/%d\x1b[0m\r\n", len(f.matches), len(f.items))
+	for i := f.offset; i < len(f.matches) && i < f.offset+listHeight; i++ {
+		label := terminal.Truncate(f.items[f.matches[i]].Label, width-2)
+		if i == f.cursor {
+			fmt.Fprintf(&b, "\x1b[7m> %s\x1b[0m\r\n", label)
+		} else {
+			fmt.Fprintf(&b, "  %s\r\n", label)
+		}
+	}
+	for i := len(f.matches) - f.offset; i < listHeight; i++ {
+		b.WriteString("\r\n")
+	}
+
+	b.WriteString(strings.Repeat("─", width) + "\r\n")
+	if len(f.matches) != 0 {
+		preview := strings.ReplaceAll(f.items[f.matches[f.cursor]].Preview, "\r", "")
+		lines := strings.Split(strings.TrimSpace(preview), "\n")
+		for i := 0; i < len(lines) && i < height-listHeight-3; i++ {
+			b.WriteString(terminal.Truncate(lines[i], width) + "\r\n")
+		}
+	}
+
+	// the query line is last so that the cursor is left on it
+	query := fmt.Sprintf("%s> %s", f.prompt, string(f.query))
+	fmt.Fprintf(&b, "\x1b[%d;1H%s", height, query)
+	t.Draw(b.String())
+	t.ShowCursor(height, len([]rune(query))+1)
+}
+
+// pickInteractive runs the fuzzy finder in the alternate screen of the terminal.
+func pickInteractive(in, out *os.File, prompt string, items []Item) (index int, err error) {
+	t, err := terminal.Open(in, out)
+	if err != nil {
+		return pickNumbered(in, out, prompt, items)
+	}
+	defer t.Close()
+
+	f := &finder{prompt: prompt, items: items}
+	f.filter()
+	for {
+		f.render(t)
+		keys, readErr := t.ReadKeys()
+		if readErr != nil {
+			return -1, readErr
+		}
+		for _, key := range keys {
+			if done, index, err := f.handle(key); done {
+				return index, err
+			}
+		}
+	}
+}
+
+// handle applies a key, and reports whether the finder is done along with its result.
+func (f *finder) handle(key string) (done bool, index int, err error) {
+	switch key {
+	case terminal.KeyEscape, terminal.KeyCtrlC:
+		return true, -1, ErrCancelled
+	case terminal.KeyEnter:
+		if len(f.matches) != 0 {
+			return true, f.matches[f.cursor], nil
+		}
+	case terminal.KeyUp, terminal.KeyCtrlP:
+		f.move(-1)
+	case terminal.KeyDown, terminal.KeyCtrlN:
+		f.move(1)
+	case terminal.KeyPageUp:
+		f.move(-10)
+	case terminal.KeyPageDown:
+		f.move(10)
+	case terminal.KeyBackspace, terminal.KeyCtrlH:
+		if len(f.query) != 0 {
+			f.query = f.query[:len(f.query)-1]
+			f.filter()
+		}
+	case terminal.KeyCtrlU:
+		f.query = nil
+		f.filter()
+	default:
+		if terminal.IsPrintable(key) {
+			f.query = append(f.query, []rune(key)...)
+			f.filter()
+		}
+	}
+	return
+}
@@ -0,0 +1,171 @@
+// Package terminal puts a terminal in full screen raw mode and reads keys from it, for the interactive views.
+package terminal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Keys that are not printable, as returned by ReadKeys.
+const (
+	KeyEscape    = "\x1b"
+	KeyCtrlC     = "\x03"
+	KeyEnter     = "\r"
+	KeyBackspace = "\x7f"
+	KeyCtrlH     = "\x08"
+	KeyCtrlN     = "\x0e"
+	KeyCtrlP     = "\x10"
+	KeyCtrlU     = "\x15"
+	KeyUp        = "\x1b[A"
+	KeyDown      = "\x1b[B"
+	KeyRight     = "\x1b[C"
+	KeyLeft      = "\x1b[D"
+	KeyPageUp    = "\x1b[5~"
+	KeyPageDown  = "\x1b[6~"
+)
+
+// applicationKeys maps the keys sent in the application cursor mode of some terminals to the usual ones.
+var applicationKeys = map[string]string{
+	"\x1bOA": KeyUp,
+	"\x1bOB": KeyDown,
+	"\x1bOC": KeyRight,
+	"\x1bOD": KeyLeft,
+	"\n":     KeyEnter,
+}
+
+// IsTerminal reports whether in and out are both terminals.
+func IsTerminal(in io.Reader, out io.Writer) bool {
+	inFile, inOK := in.(*os.File)
+	outFile, outOK := out.(*os.File)
+	return inOK && outOK && term.IsTerminal(int(inFile.Fd())) && term.IsTerminal(int(outFile.Fd()))
+}
+
+// Terminal is a terminal in raw mode showing its alternate screen.
+type Terminal struct {
+	in, out *os.File
+	state   *term.State
+	buf     []byte
+}
+
+// Open switches the terminal to raw mode and to its alternate screen, which Close restores.
+func Open(in, out *os.File) (t *Terminal, err error) {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return
+	}
+	// hide the cursor while drawing
+	_, _ = out.WriteString("\x1b[?1049h\x1b[?25l")
+	return &Terminal{in: in, out: out, state: state, buf: make([]byte, 256)}, nil
+}
+
+// Close leaves the alternate screen and restores the mode of the terminal.
+func (t *Terminal) Close() error {
+	_, _ = t.out.WriteString("\x1b[?25h\x1b[?1049l")
+	return term.Restore(int(t.in.Fd()), t.state)
+}
+
+// Size gets the width and height of the terminal, or a usual size when it cannot be known.
+func (t *Terminal) Size() (width, height int) {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width < 20 || height < 6 {
+		return 80, 24
+	}
+	return
+}
+
+// Draw replaces the content of the screen. The terminal is in raw mode, so lines must end with \r\n.
+func (t *Terminal) Draw(screen string) {
+	_, _ = t.out.WriteString("\x1b[H\x1b[2J" + screen)
+}
+
+// ShowCursor shows the cursor at the given line and column, starting from 1.
+func (t *Terminal) ShowCursor(line, column int) {
+	_, _ = fmt.Fprintf(t.out, "\x1b[%d;%dH\x1b[?25h", line, column)
+}
+
+// ErrStopped is returned by WaitInput when it is stopped before any input arrived.
+var ErrStopped = errors.New("stopped waiting for input")
+
+// WaitInput waits until there is input for ReadKeys, without consuming it, or until stop is closed.
+func (t *Terminal) WaitInput(stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return ErrStopped
+		default:
+		}
+		// wake up regularly to notice stop
+		ready, err := inputReady(t.in, 100*time.Millisecond)
+		if err != nil || ready {
+			return err
+		}
+	}
+}
+
+// ReadKeys waits for input and returns the keys it holds. Several keys arrive at once when typing fast or pasting.
+func (t *Terminal) ReadKeys() (keys []string, err error) {
+	n, err := t.in.Read(t.buf)
+	if err != nil {
+		return
+	}
+	if n == 0 {
+		return nil, errors.New("no input")
+	}
+	for input := t.buf[:n]; len(input) != 0; {
+		var key string
+		key, input = nextKey(input)
+		if mapped, ok := applicationKeys[key]; ok {
+			key = mapped
+		}
+		keys = append(keys, key)
+	}
+	return
+}
+
+// nextKey splits the first key off the input: an escape sequence, or a single rune.
+func nextKey(input []byte) (key string, rest []byte) {
+	if input[0] == 27 && len(input) > 2 && (input[1] == '[' || input[1] == 'O') {
+		for i := 2; i < len(input); i++ {
+			if input[i] >= 0x40 && input[i] <= 0x7e {
+				return string(input[:i+1]), input[i+1:]
+			}
+		}
+		return string(input), nil
+	}
+	_, size := utf8.DecodeRune(input)
+	return string(input[:size]), input[size:]
+}
+
+// IsPrintable reports whether the key is a single printable rune, as typed in a text field.
+func IsPrintable(key string) bool {
+	r, size := utf8.DecodeRuneInString(key)
+	return r != utf8.RuneError && size == len(key) && r >= ' ' && r != 127
+}
+
+// Truncate cuts s to width runes, replacing tabs that would break the layout.
+func Truncate(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	if width <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// Pad truncates s to width runes, then pads it with spaces to exactly width.
+func Pad(s string, width int) string {
+	s = Truncate(s, width)
+	for n := utf8.RuneCountInString(s); n < width; n++ {
+		s += " "
+	}
+	return s
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package terminal
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// inputReady waits at most timeout for in to have something to read.
+func inputReady(in *os.File, timeout time.Duration) (bool, error) {
+	event, err := windows.WaitForSingleObject(windows.Handle(in.Fd()), uint32(timeout.Milliseconds()))
+	if err != nil {
+		return false, err
+	}
+	return event == windows.WAIT_OBJECT_0, nil
+}
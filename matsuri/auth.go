@@ -0,0 +1,223 @@
+package matsuri
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"gopkg.in/yaml.v3"
+)
+
+// TokenPath is the path of the token file written by `git matsuri auth login`, relative to the user configuration directory.
+const TokenPath = "git-matsuri/token" // #nosec
+
+// TokenProvider is a place a GitHub token can be read from.
+type TokenProvider interface {
+	// Name describes the provider to the user.
+	Name() string
+	// Token returns the token, or an empty string if the provider has none.
+	Token() (string, error)
+}
+
+// TokenProviders returns the providers in the order they are tried.
+func TokenProviders() []TokenProvider {
+	return []TokenProvider{
+		envTokenProvider{},
+		fileTokenProvider{},
+		gitCredentialTokenProvider{},
+		ghTokenProvider{},
+	}
+}
+
+// token and tokenSource cache the result of FindToken until the token file changes.
+var (
+	tokenMu     sync.Mutex
+	token       string
+	tokenSource string
+)
+
+// forgetToken makes the next FindToken look up the providers again.
+func forgetToken() {
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	token, tokenSource = "", ""
+}
+
+// FindToken gets the first token available from TokenProviders, along with the name of its provider.
+// Providers that fail are skipped, unless they hold a token that must not be used, such as a token file readable by others.
+func FindToken() (string, string, error) {
+	tokenMu.Lock()
+	defer tokenMu.Unlock()
+	if token != "" {
+		return token, tokenSource, nil
+	}
+	for _, provider := range TokenProviders() {
+		t, err := provider.Token()
+		if errors.Is(err, errUnsafeTokenFile) {
+			return "", "", err
+		}
+		if err == nil && t != "" {
+			token, tokenSource = t, provider.Name()
+			return token, tokenSource, nil
+		}
+	}
+	return "", "", errors.New("no GitHub token found")
+}
+
+// apiHost gets the host name of the GitHub instance git-matsuri talks to.
+func apiHost() string {
+	if u, err := url.Parse(os.Getenv(APIURLName)); err == nil && u.Host != "" && u.Host != "api.github.com" {
+		return u.Hostname()
+	}
+	return "github.com"
+}
+
+type envTokenProvider struct{}
+
+func (envTokenProvider) Name() string {
+	return "the " + TokenName + " environment variable"
+}
+
+func (envTokenProvider) Token() (string, error) {
+	return os.Getenv(TokenName), nil
+}
+
+var errUnsafeTokenFile = errors.New("token file is readable by other users")
+
+// TokenFile gets the path of the token file written by `git matsuri auth login`.
+func TokenFile() (path string, err error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return
+	}
+	path = filepath.Join(dir, TokenPath)
+	return
+}
+
+type fileTokenProvider struct{}
+
+func (fileTokenProvider) Name() string {
+	path, _ := TokenFile()
+	return "the token file " + path
+}
+
+func (fileTokenProvider) Token() (t string, err error) {
+	path, err := TokenFile()
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	// Windows does not have Unix permissions
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		err = fmt.Errorf("%w: run `chmod 600 %s` or `git matsuri auth login` again", errUnsafeTokenFile, path)
+		return
+	}
+	content, err := os.ReadFile(path) // #nosec
+	t = strings.TrimSpace(string(content))
+	return
+}
+
+// SaveToken writes the token file, readable by the current user only.
+func SaveToken(t string) (path string, err error) {
+	path, err = TokenFile()
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	if err = os.WriteFile(path, []byte(t+"\n"), 0o600); err != nil {
+		return
+	}
+	// WriteFile keeps the permissions of an existing file
+	err = os.Chmod(path, 0o600)
+	forgetToken()
+	return
+}
+
+// RemoveToken deletes the token file, if it exists.
+func RemoveToken() (removed bool, err error) {
+	path, err := TokenFile()
+	if err != nil {
+		return
+	}
+	err = os.Remove(path)
+	forgetToken()
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type gitCredentialTokenProvider struct{}
+
+func (gitCredentialTokenProvider) Name() string {
+	return "git credential for " + apiHost()
+}
+
+func (gitCredentialTokenProvider) Token() (t string, err error) {
+	out, err := git.Default.Run(git.Command{
+		Args:  []string{"credential", "fill"},
+		Stdin: fmt.Sprintf("protocol=https\nhost=%s\n\n", apiHost()),
+		// never prompt, only use stored credentials
+		Env: []string{"GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never", "GIT_ASKPASS="},
+	})
+	if err != nil {
+		return
+	}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if value := strings.TrimPrefix(scanner.Text(), "password="); value != scanner.Text() {
+			t = value
+		}
+	}
+	return
+}
+
+type ghTokenProvider struct{}
+
+func ghHostsFile() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+func (ghTokenProvider) Name() string {
+	return "the GitHub CLI configuration " + ghHostsFile()
+}
+
+func (ghTokenProvider) Token() (t string, err error) {
+	content, err := os.ReadFile(ghHostsFile())
+	if err != nil {
+		return
+	}
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err = yaml.Unmarshal(content, &hosts); err != nil {
+		return
+	}
+	// recent versions of gh keep the token in the system keyring, in which case there is none here
+	t = hosts[apiHost()].OAuthToken
+	return
+}
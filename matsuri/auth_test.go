@@ -0,0 +1,146 @@
+package matsuri
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// tokenSources are the places a test can put a token in, each holding a token named after it.
+type tokenSources struct {
+	env, file, gitCredential, gh bool
+	// fileMode is the permission of the token file, 0600 when 0
+	fileMode os.FileMode
+}
+
+// setUpTokenSources makes a temporary home directory holding the given token sources.
+func setUpTokenSources(t *testing.T, sources tokenSources) (home string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GH_CONFIG_DIR", "")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv(APIURLName, "")
+	t.Setenv(TokenName, "")
+	if sources.env {
+		t.Setenv(TokenName, "env-token")
+	}
+	if sources.file {
+		path, err := SaveToken("file-token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sources.fileMode != 0 {
+			if err = os.Chmod(path, sources.fileMode); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if sources.gitCredential {
+		// a credential helper answering with a stored password, as a credential manager would
+		helper := "!f() { test \"$1\" = get && echo username=volunteer && echo password=git-token; }; f"
+		out, err := exec.Command("git", "config", "--global", "credential.helper", helper).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git config: %v\n%s", err, out)
+		}
+	}
+	if sources.gh {
+		dir := filepath.Join(home, ".config", "gh")
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			t.Fatal(err)
+		}
+		hosts := "github.com:\n    user: volunteer\n    oauth_token: gh-token\n    git_protocol: https\n"
+		if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(hosts), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// FindToken keeps the token it found for the rest of the process
+	forgetToken()
+	t.Cleanup(forgetToken)
+	return
+}
+
+func TestFindToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		sources     tokenSources
+		wantToken   string
+		wantSource  string
+		wantUnsafe  bool
+		wantMissing bool
+	}{
+		{
+			name:       "environment first",
+			sources:    tokenSources{env: true, file: true, gitCredential: true, gh: true},
+			wantToken:  "env-token",
+			wantSource: "the MATSURI_TOKEN environment variable",
+		},
+		{
+			name:       "then the token file",
+			sources:    tokenSources{file: true, gitCredential: true, gh: true},
+			wantToken:  "file-token",
+			wantSource: "the token file {home}/.config/git-matsuri/token",
+		},
+		{
+			name:       "then git credential fill",
+			sources:    tokenSources{gitCredential: true, gh: true},
+			wantToken:  "git-token",
+			wantSource: "git credential for github.com",
+		},
+		{
+			name:       "then the GitHub CLI",
+			sources:    tokenSources{gh: true},
+			wantToken:  "gh-token",
+			wantSource: "the GitHub CLI configuration {home}/.config/gh/hosts.yml",
+		},
+		{
+			name:        "no token",
+			wantMissing: true,
+		},
+		{
+			name:       "token file readable by others",
+			sources:    tokenSources{file: true, fileMode: 0o644, gitCredential: true, gh: true},
+			wantUnsafe: true,
+		},
+		{
+			name:       "token file readable by the group",
+			sources:    tokenSources{file: true, fileMode: 0o640, gh: true},
+			wantUnsafe: true,
+		},
+		{
+			name:       "unsafe token file behind the environment",
+			sources:    tokenSources{env: true, file: true, fileMode: 0o644},
+			wantToken:  "env-token",
+			wantSource: "the MATSURI_TOKEN environment variable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sources.fileMode != 0 && runtime.GOOS == "windows" {
+				t.Skip("Windows does not have Unix permissions")
+			}
+			home := setUpTokenSources(t, tt.sources)
+			token, source, err := FindToken()
+			switch {
+			case tt.wantUnsafe:
+				if !errors.Is(err, errUnsafeTokenFile) {
+					t.Errorf("FindToken() = %q, %q, %v, want the token file to be refused", token, source, err)
+				}
+			case tt.wantMissing:
+				if err == nil || err.Error() != "no GitHub token found" {
+					t.Errorf("FindToken() = %q, %q, %v, want no token", token, source, err)
+				}
+			default:
+				wantSource := strings.ReplaceAll(tt.wantSource, "{home}", home)
+				if err != nil || token != tt.wantToken || source != wantSource {
+					t.Errorf("FindToken() = %q, %q, %v, want %q from %q", token, source, err, tt.wantToken, wantSource)
+				}
+			}
+		})
+	}
+}
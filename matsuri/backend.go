@@ -0,0 +1,207 @@
+package matsuri
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v29/github"
+	"golang.org/x/oauth2"
+)
+
+// APIURLName is the environment variable name for an alternative GitHub API base URL.
+const APIURLName = "MATSURI_API_URL"
+
+// IssuesService is the subset of the GitHub Issues API used by git-matsuri.
+type IssuesService interface {
+	Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	ListByRepo(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	AddAssignees(ctx context.Context, owner, repo string, number int, assignees []string) (*github.Issue, *github.Response, error)
+	RemoveAssignees(ctx context.Context, owner, repo string, number int, assignees []string) (*github.Issue, *github.Response, error)
+	AddLabelsToIssue(ctx context.Context, owner string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error)
+	RemoveLabelForIssue(ctx context.Context, owner string, repo string, number int, label string) (*github.Response, error)
+}
+
+// OrganizationsService is the subset of the GitHub Organizations API used by git-matsuri.
+type OrganizationsService interface {
+	ListProjects(ctx context.Context, org string, opts *github.ProjectListOptions) ([]*github.Project, *github.Response, error)
+}
+
+// ProjectsService is the subset of the GitHub Projects API used by git-matsuri.
+type ProjectsService interface {
+	ListProjectColumns(ctx context.Context, projectID int64, opts *github.ListOptions) ([]*github.ProjectColumn, *github.Response, error)
+	ListProjectCards(ctx context.Context, columnID int64, opts *github.ProjectCardListOptions) ([]*github.ProjectCard, *github.Response, error)
+	CreateProjectCard(ctx context.Context, columnID int64, opts *github.ProjectCardOptions) (*github.ProjectCard, *github.Response, error)
+	MoveProjectCard(ctx context.Context, cardID int64, opts *github.ProjectCardMoveOptions) (*github.Response, error)
+}
+
+// PullRequestsService is the subset of the GitHub Pull Requests API used by git-matsuri.
+type PullRequestsService interface {
+	Create(ctx context.Context, owner string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
+	List(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	Edit(ctx context.Context, owner string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error)
+}
+
+// RepositoriesService is the subset of the GitHub Repositories API used by git-matsuri.
+type RepositoriesService interface {
+	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	GetLatestRelease(ctx context.Context, owner, repo string) (*github.RepositoryRelease, *github.Response, error)
+}
+
+// ProjectV2 is a GitHub Projects (v2) board.
+type ProjectV2 struct {
+	ID     string
+	Number int
+	Title  string
+	Closed bool
+	// StatusField is the single-select field whose options act as the columns of the board, if any.
+	StatusField *ProjectV2SingleSelectField
+}
+
+// ProjectV2SingleSelectField is a single-select field of a Projects (v2) board.
+type ProjectV2SingleSelectField struct {
+	ID      string             `json:"id"`
+	Name    string             `json:"name"`
+	Options []*ProjectV2Option `json:"options"`
+}
+
+// ProjectV2Option is an option of a single-select field.
+type ProjectV2Option struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProjectV2Item is an item of a Projects (v2) board.
+type ProjectV2Item struct {
+	ID string
+	// Status is the name of the selected Status option, empty if none is set.
+	Status string
+	// ContentType is one of Issue, PullRequest or DraftIssue.
+	ContentType string
+	// ContentID is the node ID of the issue or pull request.
+	ContentID  string
+	Number     int
+	Repository string
+	Title      string
+}
+
+// ProjectsV2Service is the subset of the GitHub Projects (v2) GraphQL API used by git-matsuri.
+type ProjectsV2Service interface {
+	ListProjects(ctx context.Context, org string) ([]*ProjectV2, error)
+	ListItems(ctx context.Context, projectID string) ([]*ProjectV2Item, error)
+	// GetItemForContent gets the item of the issue or pull request with the given node ID, or nil if it is not in the project.
+	GetItemForContent(ctx context.Context, projectID, contentID string) (*ProjectV2Item, error)
+	AddItem(ctx context.Context, projectID, contentID string) (*ProjectV2Item, error)
+	SetItemStatus(ctx context.Context, projectID, itemID, fieldID, optionID string) error
+}
+
+// UsersService is the subset of the GitHub Users API used by git-matsuri.
+type UsersService interface {
+	Get(ctx context.Context, user string) (*github.User, *github.Response, error)
+	ListEmails(ctx context.Context, opts *github.ListOptions) ([]*github.UserEmail, *github.Response, error)
+}
+
+// RateLimitsService gets the rate limits of the GitHub API for the current token.
+type RateLimitsService interface {
+	RateLimits(ctx context.Context) (*github.RateLimits, *github.Response, error)
+}
+
+// Backend groups the GitHub services git-matsuri talks to.
+// The services of a *github.Client satisfy it directly, and package fake provides an in-memory one.
+type Backend struct {
+	Issues        IssuesService
+	Organizations OrganizationsService
+	Projects      ProjectsService
+	ProjectsV2    ProjectsV2Service
+	PullRequests  PullRequestsService
+	RateLimits    RateLimitsService
+	Repositories  RepositoriesService
+	Users         UsersService
+}
+
+var (
+	backend     *Backend
+	backendOnce sync.Once
+)
+
+// NewGitHubBackend creates a Backend for the GitHub API at baseURL, or api.github.com if it is empty.
+func NewGitHubBackend(httpClient *http.Client, baseURL string) (b *Backend, err error) {
+	client := github.NewClient(httpClient)
+	if baseURL != "" {
+		if !strings.HasSuffix(baseURL, "/") {
+			baseURL += "/"
+		}
+		var u *url.URL
+		u, err = url.Parse(baseURL)
+		if err != nil {
+			return
+		}
+		client.BaseURL = u
+	}
+	b = &Backend{
+		Issues:        client.Issues,
+		Organizations: client.Organizations,
+		Projects:      client.Projects,
+		ProjectsV2:    &projectsV2Service{gql: &graphQLClient{client: client}},
+		PullRequests:  client.PullRequests,
+		RateLimits:    client,
+		Repositories:  client.Repositories,
+		Users:         client.Users,
+	}
+	return
+}
+
+// NewDefaultBackend creates a Backend authenticated with the token from FindToken against MATSURI_API_URL, if set.
+func NewDefaultBackend() (*Backend, error) {
+	return NewGitHubBackend(tokenClient(), os.Getenv(APIURLName))
+}
+
+// NewTokenBackend creates a Backend authenticated with the given token against MATSURI_API_URL, if set.
+func NewTokenBackend(t string) (*Backend, error) {
+	return NewGitHubBackend(newTokenClient(t), os.Getenv(APIURLName))
+}
+
+func tokenClient() *http.Client {
+	t, _, _ := FindToken()
+	return newTokenClient(t)
+}
+
+func newTokenClient(t string) *http.Client {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: t})
+	transport := http.DefaultTransport
+	if CacheEnabled {
+		transport = newCacheTransport(transport)
+	}
+	transport = newRetryTransport(transport)
+	// the cache sits below the token so that it can tell the responses of different tokens apart
+	return &http.Client{Transport: &oauth2.Transport{Source: ts, Base: transport}}
+}
+
+// SetBackend replaces the Backend used by every function of this package.
+func SetBackend(b *Backend) {
+	backendOnce.Do(func() {})
+	backend = b
+}
+
+// HasBackend reports whether a Backend has already been set.
+func HasBackend() bool {
+	return backend != nil
+}
+
+// GetClient retrieves the current Backend, creating the default one if none has been set.
+func GetClient() (client *Backend) {
+	backendOnce.Do(func() {
+		b, err := NewDefaultBackend()
+		if err != nil {
+			// an invalid MATSURI_API_URL is reported by the root command, fall back to api.github.com
+			b, _ = NewGitHubBackend(tokenClient(), "")
+		}
+		backend = b
+	})
+	client = backend
+	return
+}
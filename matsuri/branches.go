@@ -0,0 +1,109 @@
+package matsuri
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// States of topic branches, from their issue and pull requests.
+const (
+	// BranchMerged branches have a merged pull request.
+	BranchMerged = "merged"
+	// BranchClosed branches belong to a closed issue, without any merged pull request.
+	BranchClosed = "closed"
+	// BranchOpen branches belong to an open issue, or have an open pull request.
+	BranchOpen = "open"
+	// BranchOrphaned branches belong to an issue that was deleted or transferred to another repository.
+	BranchOrphaned = "orphaned"
+)
+
+// BranchStates lists the states of topic branches, from the safest to delete.
+var BranchStates = []string{BranchMerged, BranchClosed, BranchOrphaned, BranchOpen}
+
+// BranchState tells what became of the issue and pull requests of a topic branch.
+type BranchState struct {
+	Branch string
+	Issue  int
+	State  string
+	// PullRequest is the number of the merged pull request, or else of the latest one, 0 if there is none.
+	PullRequest int
+	// Head is the last commit of the merged pull request, empty if none was merged.
+	Head string
+}
+
+// isGone reports whether the GitHub API answered that the resource does not exist anymore.
+func isGone(err error) bool {
+	var responseErr *github.ErrorResponse
+	return errors.As(err, &responseErr) && responseErr.Response != nil &&
+		(responseErr.Response.StatusCode == http.StatusNotFound || responseErr.Response.StatusCode == http.StatusGone)
+}
+
+// GetBranchStates looks up the issue and pull requests of each topic branch of the current repository.
+// Branches that could not be looked up are left out and reported together in err.
+func GetBranchStates(branches []string) (states []*BranchState, err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	issues := map[int]*github.Issue{}
+	var fetchErrs FetchErrors
+	for _, branch := range branches {
+		number, ok := IssueNumberFromBranch(branch)
+		if !ok {
+			continue
+		}
+		state := &BranchState{Branch: branch, Issue: number}
+		issue, fetched := issues[number]
+		if !fetched {
+			var getErr error
+			issue, _, getErr = client.Issues.Get(ctx, owner(), repoName, number)
+			if getErr != nil && !isGone(getErr) {
+				fetchErrs = append(fetchErrs, fmt.Errorf("%s#%d: %w", repoName, number, getErr))
+				continue
+			}
+			issues[number] = issue
+		}
+		// transferred issues are redirected to their new repository, possibly of another organization
+		transferred := issue != nil && !strings.HasSuffix(strings.ToLower(issue.GetRepositoryURL()), strings.ToLower("/"+owner()+"/"+repoName))
+		if issue == nil || issue.IsPullRequest() || transferred {
+			state.State = BranchOrphaned
+			states = append(states, state)
+			continue
+		}
+		pulls, listErr := GetPullRequestsForBranch(branch)
+		if listErr != nil {
+			fetchErrs = append(fetchErrs, fmt.Errorf("%s: %w", branch, listErr))
+			continue
+		}
+		if len(pulls) != 0 {
+			state.PullRequest = pulls[0].GetNumber()
+		}
+		for _, pr := range pulls {
+			if pr.MergedAt != nil && state.Head == "" {
+				state.PullRequest, state.Head = pr.GetNumber(), pr.GetHead().GetSHA()
+			}
+			if pr.GetState() == "open" {
+				state.State = BranchOpen
+			}
+		}
+		switch {
+		case state.State != "":
+		case state.Head != "":
+			state.State = BranchMerged
+		case issue.GetState() == "open":
+			state.State = BranchOpen
+		default:
+			state.State = BranchClosed
+		}
+		states = append(states, state)
+	}
+	if len(fetchErrs) != 0 {
+		err = fetchErrs
+	}
+	return
+}
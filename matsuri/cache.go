@@ -0,0 +1,146 @@
+package matsuri
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+)
+
+// CacheEnabled controls whether the GitHub API responses are cached on disk.
+var CacheEnabled = true
+
+// CacheDir returns the directory holding the cached GitHub API responses.
+func CacheDir() (dir string, err error) {
+	dir, err = os.UserCacheDir()
+	if err != nil {
+		return
+	}
+	dir = filepath.Join(dir, "git-matsuri", "http")
+	return
+}
+
+// ClearCache removes every cached GitHub API response.
+func ClearCache() (err error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return
+	}
+	return os.RemoveAll(dir)
+}
+
+// cacheTransport caches the responses to GET requests that carry an ETag or Last-Modified header,
+// and revalidates them with conditional requests. GitHub does not count 304 responses against the rate limit.
+type cacheTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+func newCacheTransport(base http.RoundTripper) http.RoundTripper {
+	dir, err := CacheDir()
+	if err != nil {
+		return base
+	}
+	return &cacheTransport{dir: dir, base: base}
+}
+
+// path gets the cache file of a request. Responses depend on the token and the requested media type,
+// so both are part of the key.
+func (t *cacheTransport) path(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return filepath.Join(t.dir, hex.EncodeToString(h.Sum(nil)))
+}
+
+func (t *cacheTransport) load(path string, req *http.Request) *http.Response {
+	content, err := os.ReadFile(path) // #nosec
+	if err != nil {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(content)), req)
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+func (t *cacheTransport) store(path string, resp *http.Response) (err error) {
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(t.dir, 0o700); err != nil {
+		return
+	}
+	// write then rename so that concurrent readers never see a partial file
+	tmp, err := os.CreateTemp(t.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(dump); err != nil {
+		tmp.Close()
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+	path := t.path(req)
+	cached := t.load(path, req)
+	if cached != nil {
+		// RoundTrip must not modify the caller's request
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		if cached != nil {
+			cached.Body.Close()
+		}
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		// keep the fresh rate limit headers along with the cached content
+		for key, values := range resp.Header {
+			cached.Header[key] = values
+		}
+		resp.Body.Close()
+		cached.Request = req
+		return cached, nil
+	}
+	if cached != nil {
+		cached.Body.Close()
+	}
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		// failing to cache is not an error, the response is simply not cached
+		_ = t.store(path, resp)
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return resp, nil
+}
@@ -0,0 +1,174 @@
+package matsuri
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+// conditionalServer answers with an ETag, and with 304 Not Modified when the request has the current one.
+type conditionalServer struct {
+	mu          sync.Mutex
+	version     int
+	etag        bool
+	requests    int
+	notModified int
+	// conditions are the If-None-Match headers received, in order
+	conditions []string
+}
+
+func (s *conditionalServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.conditions = append(s.conditions, r.Header.Get("If-None-Match"))
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(5000-s.requests))
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if s.etag {
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	}
+	fmt.Fprintf(w, "%s version %d for %s", r.URL.Path, s.version, r.Header.Get("Authorization"))
+}
+
+func (s *conditionalServer) setVersion(version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+func (s *conditionalServer) counts() (requests, notModified int, conditions []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests, s.notModified, append([]string(nil), s.conditions...)
+}
+
+func TestCacheTransport(t *testing.T) {
+	type step struct {
+		method, path, auth string
+		// version is the version of the content on the server for this request
+		version         int
+		wantBody        string
+		wantNotModified bool
+	}
+	tests := []struct {
+		name  string
+		etag  bool
+		steps []step
+	}{
+		{
+			name: "revalidates cached responses",
+			etag: true,
+			steps: []step{
+				{method: "GET", path: "/issues", auth: "a", wantBody: "/issues version 0 for a"},
+				{method: "GET", path: "/issues", auth: "a", wantBody: "/issues version 0 for a", wantNotModified: true},
+				{method: "GET", path: "/issues", auth: "a", version: 1, wantBody: "/issues version 1 for a"},
+				{method: "GET", path: "/issues", auth: "a", version: 1, wantBody: "/issues version 1 for a", wantNotModified: true},
+			},
+		},
+		{
+			name: "keeps the responses of each token apart",
+			etag: true,
+			steps: []step{
+				{method: "GET", path: "/user", auth: "a", wantBody: "/user version 0 for a"},
+				{method: "GET", path: "/user", auth: "b", wantBody: "/user version 0 for b"},
+				{method: "GET", path: "/user", auth: "a", wantBody: "/user version 0 for a", wantNotModified: true},
+			},
+		},
+		{
+			name: "does not cache writes",
+			etag: true,
+			steps: []step{
+				{method: "POST", path: "/issues", auth: "a", wantBody: "/issues version 0 for a"},
+				{method: "POST", path: "/issues", auth: "a", wantBody: "/issues version 0 for a"},
+			},
+		},
+		{
+			name: "does not cache responses without validators",
+			steps: []step{
+				{method: "GET", path: "/rate_limit", auth: "a", wantBody: "/rate_limit version 0 for a"},
+				{method: "GET", path: "/rate_limit", auth: "a", wantBody: "/rate_limit version 0 for a"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &conditionalServer{etag: tt.etag}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+			transport := &cacheTransport{dir: t.TempDir(), base: http.DefaultTransport}
+			for i, s := range tt.steps {
+				server.setVersion(s.version)
+				_, before, _ := server.counts()
+				req, err := http.NewRequest(s.method, ts.URL+s.path, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", s.auth)
+				resp, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+				body, err := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+				if resp.StatusCode != http.StatusOK || string(body) != s.wantBody {
+					t.Errorf("step %d: got %d %q, want 200 %q", i, resp.StatusCode, body, s.wantBody)
+				}
+				_, after, _ := server.counts()
+				if notModified := after > before; notModified != s.wantNotModified {
+					t.Errorf("step %d: answered from the cache = %v, want %v", i, notModified, s.wantNotModified)
+				}
+				if req.Header.Get("If-None-Match") != "" {
+					t.Errorf("step %d: the caller's request was modified", i)
+				}
+				// the rate limit headers are the fresh ones, even when the content comes from the cache
+				requests, _, _ := server.counts()
+				if got, want := resp.Header.Get("X-RateLimit-Remaining"), fmt.Sprint(5000-requests); got != want {
+					t.Errorf("step %d: X-RateLimit-Remaining = %q, want %q", i, got, want)
+				}
+			}
+			if requests, _, _ := server.counts(); requests != len(tt.steps) {
+				t.Errorf("the server got %d requests, want %d", requests, len(tt.steps))
+			}
+		})
+	}
+}
+
+func TestCacheTransportCorruptFile(t *testing.T) {
+	server := &conditionalServer{etag: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	transport := &cacheTransport{dir: t.TempDir(), base: http.DefaultTransport}
+	get := func() string {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/issues", nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	get()
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/issues", nil)
+	if err := os.WriteFile(transport.path(req), []byte("not an HTTP response"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(); got != "/issues version 0 for " {
+		t.Errorf("got %q after corrupting the cache", got)
+	}
+	if _, _, conditions := server.counts(); conditions[1] != "" {
+		t.Errorf("a corrupt cache file sent If-None-Match %q", conditions[1])
+	}
+}
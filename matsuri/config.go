@@ -0,0 +1,178 @@
+package matsuri
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// UserConfigPath is the path of the user configuration file, relative to the user configuration directory.
+	UserConfigPath = "git-matsuri/config.yml"
+	// RepoConfigName is the name of the repository configuration file, at the root of the repository.
+	RepoConfigName = ".matsuri.yml"
+)
+
+// Columns holds the names of the project columns used by the workflow.
+type Columns struct {
+	Todo       string `yaml:"todo"`
+	InProgress string `yaml:"in_progress"`
+	Done       string `yaml:"done"`
+}
+
+// Config holds the settings that can be overridden per user or per repository.
+type Config struct {
+	// Owner is the GitHub organization owning the repositories and projects.
+	Owner string `yaml:"owner"`
+	// ProjectPattern is a regular expression matching the names of the project boards to use.
+	ProjectPattern string `yaml:"project_pattern"`
+	// ClassicProjects selects classic projects instead of Projects (v2).
+	ClassicProjects bool    `yaml:"classic_projects"`
+	Columns         Columns `yaml:"columns"`
+	// EmailPattern is a regular expression matching the organization email addresses.
+	EmailPattern string `yaml:"email_pattern"`
+	// BranchFormat is the format of topic branch names, with a single %d for the issue number.
+	BranchFormat string `yaml:"branch_format"`
+	// YearBranchFormat is the format of the branches of each festival year, with a single %d for the year.
+	// Repositories without such branches use their default branch.
+	YearBranchFormat string `yaml:"year_branch_format"`
+	// InProgressLabel is added to issues while someone works on them, if set.
+	InProgressLabel string `yaml:"in_progress_label"`
+
+	projectRegex *regexp.Regexp
+	emailRegex   *regexp.Regexp
+	branchRegex  *regexp.Regexp
+}
+
+var (
+	config     *Config
+	configOnce sync.Once
+)
+
+// DefaultConfig returns the settings used when no configuration file overrides them.
+func DefaultConfig() *Config {
+	return &Config{
+		Owner:          "MatsuriJapon",
+		ProjectPattern: `^Matsuri.*$`,
+		Columns: Columns{
+			Todo:       "To do",
+			InProgress: "In progress",
+			Done:       "Done",
+		},
+		EmailPattern:     `^[\w._+-]+@festivaljapon\.com$`,
+		BranchFormat:     "ISSUE-%d",
+		YearBranchFormat: "v%d",
+	}
+}
+
+// ConfigPaths returns the configuration files in the order they are applied, whether they exist or not.
+func ConfigPaths() (paths []string) {
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, filepath.Join(dir, UserConfigPath))
+	}
+	if root, err := git.TopLevel(""); err == nil {
+		paths = append(paths, filepath.Join(root, RepoConfigName))
+	}
+	return
+}
+
+// LoadConfig loads the default settings, overridden by the user configuration file and then by the repository one.
+func LoadConfig() (c *Config, err error) {
+	c = DefaultConfig()
+	for _, path := range ConfigPaths() {
+		content, readErr := os.ReadFile(path) // #nosec
+		if errors.Is(readErr, os.ErrNotExist) {
+			continue
+		}
+		if readErr != nil {
+			err = readErr
+			return
+		}
+		if err = yaml.Unmarshal(content, c); err != nil {
+			err = fmt.Errorf("invalid configuration file %s: %s", path, err.Error())
+			return
+		}
+	}
+	err = c.compile()
+	return
+}
+
+func (c *Config) compile() (err error) {
+	if c.projectRegex, err = regexp.Compile(c.ProjectPattern); err != nil {
+		return fmt.Errorf("invalid project_pattern: %s", err.Error())
+	}
+	if c.emailRegex, err = regexp.Compile(c.EmailPattern); err != nil {
+		return fmt.Errorf("invalid email_pattern: %s", err.Error())
+	}
+	if strings.Count(c.BranchFormat, "%d") != 1 || strings.Count(c.BranchFormat, "%") != 1 {
+		return fmt.Errorf("invalid branch_format %q: it must contain %%d exactly once", c.BranchFormat)
+	}
+	if c.YearBranchFormat != "" && (strings.Count(c.YearBranchFormat, "%d") != 1 || strings.Count(c.YearBranchFormat, "%") != 1) {
+		return fmt.Errorf("invalid year_branch_format %q: it must contain %%d exactly once", c.YearBranchFormat)
+	}
+	// topic branches may carry a suffix, such as ISSUE-12-fix or ISSUE-12-part2
+	parts := strings.SplitN(c.BranchFormat, "%d", 2)
+	c.branchRegex = regexp.MustCompile(`^` + regexp.QuoteMeta(parts[0]) + `(\d+)` + regexp.QuoteMeta(parts[1]) + `(?:-[\w.-]+)?$`)
+	return
+}
+
+// SetConfig replaces the settings used by every function of this package.
+func SetConfig(c *Config) (err error) {
+	if err = c.compile(); err != nil {
+		return
+	}
+	// settings set explicitly are never replaced by the loaded ones
+	configOnce.Do(func() {})
+	config = c
+	// the base branch depends on the settings and on --year
+	forgetBaseBranch()
+	return
+}
+
+// GetConfig retrieves the current settings, loading them if they have not been set.
+// It is safe to call from several goroutines, including before the root command ran.
+func GetConfig() *Config {
+	configOnce.Do(func() {
+		c, err := LoadConfig()
+		if err != nil {
+			// invalid configuration files are reported by the root command
+			c = DefaultConfig()
+			_ = c.compile()
+		}
+		config = c
+	})
+	return config
+}
+
+// BranchName gets the name of the topic branch for an issue.
+func BranchName(issueNumber int) string {
+	return fmt.Sprintf(GetConfig().BranchFormat, issueNumber)
+}
+
+// IssueNumberFromBranch gets the issue number of a topic branch, named after BranchName with an optional suffix.
+func IssueNumberFromBranch(branch string) (issueNumber int, ok bool) {
+	match := GetConfig().branchRegex.FindStringSubmatch(branch)
+	if match == nil {
+		return
+	}
+	issueNumber, err := strconv.Atoi(match[1])
+	return issueNumber, err == nil && issueNumber > 0
+}
+
+// BranchPatterns describes the topic branch names IssueNumberFromBranch understands, N being the issue number.
+func BranchPatterns() []string {
+	format := strings.Replace(GetConfig().BranchFormat, "%d", "N", 1)
+	return []string{format, format + "-fix", format + "-<suffix>"}
+}
+
+func owner() string {
+	return GetConfig().Owner
+}
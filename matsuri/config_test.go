@@ -0,0 +1,155 @@
+package matsuri
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// chdirTestRepo creates a git repository holding the given repository configuration file, if any, and makes it the
+// current directory. The user configuration directory is emptied, and holds userConfig if any.
+func chdirTestRepo(t *testing.T, userConfig, repoConfig string) {
+	t.Helper()
+	root := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, "config"))
+	t.Setenv("HOME", filepath.Join(root, "home"))
+	repo := filepath.Join(root, "repo")
+	if out, err := exec.Command("git", "init", "--quiet", repo).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	write := func(path, content string) {
+		if content == "" {
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(root, "config", UserConfigPath), userConfig)
+	write(filepath.Join(repo, RepoConfigName), repoConfig)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		userConfig string
+		repoConfig string
+		// change edits the default settings into the expected ones
+		change  func(c *Config)
+		wantErr string
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{
+			name:       "user settings",
+			userConfig: "owner: Festival\nclassic_projects: true\n",
+			change: func(c *Config) {
+				c.Owner = "Festival"
+				c.ClassicProjects = true
+			},
+		},
+		{
+			name:       "repository settings override the user ones",
+			userConfig: "owner: Festival\ncolumns:\n  todo: Backlog\n",
+			repoConfig: "owner: Matsuri\nbranch_format: web-%d\n",
+			change: func(c *Config) {
+				c.Owner = "Matsuri"
+				c.Columns.Todo = "Backlog"
+				c.BranchFormat = "web-%d"
+			},
+		},
+		{
+			name:       "columns are merged",
+			repoConfig: "columns:\n  in_progress: Doing\n",
+			change:     func(c *Config) { c.Columns.InProgress = "Doing" },
+		},
+		{name: "invalid yaml", repoConfig: "owner: [\n", wantErr: "invalid configuration file"},
+		{name: "unknown type", userConfig: "classic_projects: maybe\n", wantErr: "invalid configuration file"},
+		{name: "invalid project pattern", repoConfig: "project_pattern: \"(\"\n", wantErr: "invalid project_pattern"},
+		{name: "invalid email pattern", userConfig: "email_pattern: \"[\"\n", wantErr: "invalid email_pattern"},
+		{name: "invalid branch format", repoConfig: "branch_format: ISSUE\n", wantErr: "invalid branch_format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdirTestRepo(t, tt.userConfig, tt.repoConfig)
+			got, err := LoadConfig()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("LoadConfig() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			want := DefaultConfig()
+			tt.change(want)
+			if err = want.compile(); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestIssueNumberFromBranch(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	tests := []struct {
+		format     string
+		branch     string
+		wantNumber int
+		wantOK     bool
+	}{
+		{format: "ISSUE-%d", branch: "ISSUE-12", wantNumber: 12, wantOK: true},
+		{format: "ISSUE-%d", branch: "ISSUE-12-fix", wantNumber: 12, wantOK: true},
+		{format: "ISSUE-%d", branch: "ISSUE-12-part.2", wantNumber: 12, wantOK: true},
+		{format: "ISSUE-%d", branch: "ISSUE-0"},
+		{format: "ISSUE-%d", branch: "ISSUE-"},
+		{format: "ISSUE-%d", branch: "ISSUE-12-"},
+		{format: "ISSUE-%d", branch: "ISSUE-12/fix"},
+		{format: "ISSUE-%d", branch: "feature/ISSUE-12"},
+		{format: "ISSUE-%d", branch: "master"},
+		{format: "ISSUE-%d", branch: ""},
+		{format: "feature/%d-work", branch: "feature/7-work", wantNumber: 7, wantOK: true},
+		{format: "feature/%d-work", branch: "feature/7-work-fix", wantNumber: 7, wantOK: true},
+		{format: "feature/%d-work", branch: "feature/7"},
+		{format: "bug.%d", branch: "bugX7"},
+	}
+	for _, tt := range tests {
+		c := DefaultConfig()
+		c.BranchFormat = tt.format
+		if err := SetConfig(c); err != nil {
+			t.Fatal(err)
+		}
+		number, ok := IssueNumberFromBranch(tt.branch)
+		if ok != tt.wantOK || ok && number != tt.wantNumber {
+			t.Errorf("IssueNumberFromBranch(%q) with format %q = %d, %v, want %d, %v", tt.branch, tt.format, number, ok, tt.wantNumber, tt.wantOK)
+		}
+	}
+}
+
+func TestSetConfigRejectsInvalidFormats(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	for _, format := range []string{"ISSUE", "ISSUE-%d-%d", "%s-%d"} {
+		c := DefaultConfig()
+		c.BranchFormat = format
+		if err := SetConfig(c); err == nil {
+			t.Errorf("SetConfig() accepted the branch format %q", format)
+		}
+	}
+}
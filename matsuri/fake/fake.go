@@ -0,0 +1,896 @@
+// Package fake provides an in-memory stand-in for the parts of the GitHub API used by git-matsuri,
+// so that commands can be exercised end-to-end without a token or network access.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/google/go-github/v29/github"
+)
+
+// GitHub holds the state of the fake API. It is safe for concurrent use:
+// everything it returns is a copy, which callers may keep or modify without affecting it.
+type GitHub struct {
+	// Owner is the organization every repository and project belongs to.
+	Owner string
+	// BaseURL is the prefix used for the API URLs of issues and cards.
+	BaseURL string
+	// Login is the login of the authenticated user.
+	Login string
+	// Scopes are the OAuth scopes of the token, reported in X-OAuth-Scopes. They are nil for a fine-grained token.
+	Scopes []string
+
+	mu       sync.Mutex
+	lastID   int64
+	repos    map[string]*github.Repository
+	issues   map[string]map[int]*github.Issue
+	pulls    map[string]map[int]*github.PullRequest
+	projects []*github.Project
+	columns  map[int64][]*github.ProjectColumn
+	cards    map[int64][]*github.ProjectCard
+	releases map[string]*github.RepositoryRelease
+	emails   []*github.UserEmail
+	// projectsV2 and their items are kept in creation order
+	projectsV2 []*matsuri.ProjectV2
+	items      map[string][]*matsuri.ProjectV2Item
+}
+
+// New creates an empty fake for the MatsuriJapon organization.
+func New() *GitHub {
+	return &GitHub{
+		Owner:    "MatsuriJapon",
+		BaseURL:  "https://api.github.com/",
+		Login:    "volunteer",
+		repos:    map[string]*github.Repository{},
+		issues:   map[string]map[int]*github.Issue{},
+		pulls:    map[string]map[int]*github.PullRequest{},
+		columns:  map[int64][]*github.ProjectColumn{},
+		cards:    map[int64][]*github.ProjectCard{},
+		releases: map[string]*github.RepositoryRelease{},
+		items:    map[string][]*matsuri.ProjectV2Item{},
+	}
+}
+
+// Backend returns a matsuri.Backend backed by this fake.
+func (g *GitHub) Backend() *matsuri.Backend {
+	return &matsuri.Backend{
+		Issues:        &issuesService{g},
+		Organizations: &organizationsService{g},
+		Projects:      &projectsService{g},
+		ProjectsV2:    &projectsV2Service{g},
+		PullRequests:  &pullRequestsService{g},
+		RateLimits:    &rateLimitsService{},
+		Repositories:  &repositoriesService{g},
+		Users:         &usersService{g},
+	}
+}
+
+// clone deep copies v, so that the state of the fake is never shared with callers.
+func clone[T any](v T) (c T) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	if err = json.Unmarshal(b, &c); err != nil {
+		panic(err)
+	}
+	return
+}
+
+func (g *GitHub) nextID() int64 {
+	g.lastID++
+	return g.lastID
+}
+
+func (g *GitHub) repoURL(repo string) string {
+	return fmt.Sprintf("%srepos/%s/%s", g.BaseURL, g.Owner, repo)
+}
+
+// AddRepo registers a repository with the given default branch.
+func (g *GitHub) AddRepo(name, defaultBranch string) *github.Repository {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	repo := &github.Repository{
+		ID:            github.Int64(g.nextID()),
+		Name:          github.String(name),
+		FullName:      github.String(g.Owner + "/" + name),
+		DefaultBranch: github.String(defaultBranch),
+		CloneURL:      github.String(fmt.Sprintf("https://github.com/%s/%s.git", g.Owner, name)),
+		SSHURL:        github.String(fmt.Sprintf("git@github.com:%s/%s.git", g.Owner, name)),
+		URL:           github.String(g.repoURL(name)),
+	}
+	g.repos[name] = repo
+	g.issues[name] = map[int]*github.Issue{}
+	g.pulls[name] = map[int]*github.PullRequest{}
+	return clone(repo)
+}
+
+func (g *GitHub) addIssue(repo, title string) *github.Issue {
+	number := len(g.issues[repo]) + 1
+	now := time.Now()
+	id := g.nextID()
+	issue := &github.Issue{
+		ID:            github.Int64(id),
+		NodeID:        github.String(fmt.Sprintf("I_%d", id)),
+		Number:        github.Int(number),
+		Title:         github.String(title),
+		State:         github.String("open"),
+		URL:           github.String(fmt.Sprintf("%s/issues/%d", g.repoURL(repo), number)),
+		HTMLURL:       github.String(fmt.Sprintf("https://github.com/%s/%s/issues/%d", g.Owner, repo, number)),
+		RepositoryURL: github.String(g.repoURL(repo)),
+		CreatedAt:     &now,
+		UpdatedAt:     &now,
+	}
+	g.issues[repo][number] = issue
+	return issue
+}
+
+// AddIssue creates an open issue in the given repository, which must have been added with AddRepo.
+func (g *GitHub) AddIssue(repo, title string) *github.Issue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return clone(g.addIssue(repo, title))
+}
+
+// AddProject creates an open organization project with the given columns.
+func (g *GitHub) AddProject(name string, columns ...string) *github.Project {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	project := &github.Project{
+		ID:    github.Int64(g.nextID()),
+		Name:  github.String(name),
+		State: github.String("open"),
+	}
+	g.projects = append(g.projects, project)
+	for _, columnName := range columns {
+		g.columns[project.GetID()] = append(g.columns[project.GetID()], &github.ProjectColumn{
+			ID:   github.Int64(g.nextID()),
+			Name: github.String(columnName),
+		})
+	}
+	return clone(project)
+}
+
+// Column returns the column of a project by name, or nil if it does not exist.
+func (g *GitHub) Column(project *github.Project, name string) *github.ProjectColumn {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, column := range g.columns[project.GetID()] {
+		if column.GetName() == name {
+			return clone(column)
+		}
+	}
+	return nil
+}
+
+// AddCard adds a card for an existing issue at the bottom of the given column.
+func (g *GitHub) AddCard(column *github.ProjectColumn, repo string, number int) *github.ProjectCard {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	card := &github.ProjectCard{
+		ID:         github.Int64(g.nextID()),
+		ContentURL: github.String(fmt.Sprintf("%s/issues/%d", g.repoURL(repo), number)),
+		ColumnID:   github.Int64(column.GetID()),
+	}
+	g.cards[column.GetID()] = append(g.cards[column.GetID()], card)
+	return clone(card)
+}
+
+// Cards returns a copy of the cards currently in the given column.
+func (g *GitHub) Cards(column *github.ProjectColumn) []*github.ProjectCard {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return clone(g.cards[column.GetID()])
+}
+
+// Issue returns an issue or pull request by number, or nil if it does not exist.
+func (g *GitHub) Issue(repo string, number int) *github.Issue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if issue, found := g.issues[repo][number]; found {
+		return clone(issue)
+	}
+	return nil
+}
+
+// PullRequests returns the pull requests of a repository ordered by number.
+func (g *GitHub) PullRequests(repo string) (pulls []*github.PullRequest) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, pr := range g.pulls[repo] {
+		pulls = append(pulls, clone(pr))
+	}
+	sort.Slice(pulls, func(i, j int) bool {
+		return pulls[i].GetNumber() < pulls[j].GetNumber()
+	})
+	return
+}
+
+// MergePullRequest merges a pull request whose head branch pointed to headSHA, and closes it.
+func (g *GitHub) MergePullRequest(repo string, number int, headSHA string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	pr := g.pulls[repo][number]
+	pr.Head.SHA = github.String(headSHA)
+	pr.State = github.String("closed")
+	pr.Merged = github.Bool(true)
+	pr.MergedAt = &now
+	pr.ClosedAt = &now
+	g.issues[repo][number].State = github.String("closed")
+}
+
+// AddProjectV2 creates an open Projects (v2) board whose Status field has the given options.
+func (g *GitHub) AddProjectV2(title string, statuses ...string) *matsuri.ProjectV2 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	project := &matsuri.ProjectV2{
+		ID:     fmt.Sprintf("PVT_%d", g.nextID()),
+		Number: len(g.projectsV2) + 1,
+		Title:  title,
+		StatusField: &matsuri.ProjectV2SingleSelectField{
+			ID:   fmt.Sprintf("PVTSSF_%d", g.nextID()),
+			Name: "Status",
+		},
+	}
+	for _, status := range statuses {
+		project.StatusField.Options = append(project.StatusField.Options, &matsuri.ProjectV2Option{
+			ID:   fmt.Sprintf("%x", g.nextID()),
+			Name: status,
+		})
+	}
+	g.projectsV2 = append(g.projectsV2, project)
+	return clone(project)
+}
+
+// AddItem adds an existing issue or pull request to a Projects (v2) board with the given status.
+func (g *GitHub) AddItem(project *matsuri.ProjectV2, repo string, number int, status string) *matsuri.ProjectV2Item {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	item := g.newItem(g.issues[repo][number].GetNodeID())
+	item.Status = status
+	g.items[project.ID] = append(g.items[project.ID], item)
+	return clone(item)
+}
+
+// Items returns a copy of the items of a Projects (v2) board.
+func (g *GitHub) Items(project *matsuri.ProjectV2) (items []*matsuri.ProjectV2Item) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, item := range g.items[project.ID] {
+		items = append(items, clone(item))
+	}
+	return
+}
+
+// newItem creates an item for the issue or pull request with the given node ID, or returns nil if there is none.
+func (g *GitHub) newItem(contentID string) *matsuri.ProjectV2Item {
+	for repo, issues := range g.issues {
+		for _, issue := range issues {
+			if issue.GetNodeID() != contentID {
+				continue
+			}
+			contentType := "Issue"
+			if issue.IsPullRequest() {
+				contentType = "PullRequest"
+			}
+			return &matsuri.ProjectV2Item{
+				ID:          fmt.Sprintf("PVTI_%d", g.nextID()),
+				ContentType: contentType,
+				ContentID:   contentID,
+				Number:      issue.GetNumber(),
+				Repository:  repo,
+				Title:       issue.GetTitle(),
+			}
+		}
+	}
+	return nil
+}
+
+// AddEmail adds a verified email address to the authenticated user.
+func (g *GitHub) AddEmail(email string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.emails = append(g.emails, &github.UserEmail{
+		Email:    github.String(email),
+		Verified: github.Bool(true),
+	})
+}
+
+// SetLatestRelease sets the tag of the latest release of a repository.
+func (g *GitHub) SetLatestRelease(repo, tag string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.releases[repo] = &github.RepositoryRelease{
+		ID:      github.Int64(g.nextID()),
+		TagName: github.String(tag),
+	}
+}
+
+func ok() *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}}
+}
+
+// paginate returns the page of items requested by opts, following the GitHub defaults,
+// with a Response pointing at the next page if there is one.
+func paginate[T any](items []T, opts *github.ListOptions) ([]T, *github.Response) {
+	page, size := 1, 30
+	if opts != nil {
+		if opts.Page > 0 {
+			page = opts.Page
+		}
+		if opts.PerPage > 0 {
+			size = opts.PerPage
+		}
+	}
+	resp := ok()
+	start := (page - 1) * size
+	if start >= len(items) {
+		return nil, resp
+	}
+	end := start + size
+	if end < len(items) {
+		resp.NextPage = page + 1
+	} else {
+		end = len(items)
+	}
+	return append([]T(nil), items[start:end]...), resp
+}
+
+func errorResponse(status int, method, path, message string) error {
+	req, _ := http.NewRequest(method, "https://api.github.com/"+path, nil)
+	return &github.ErrorResponse{
+		Response: &http.Response{StatusCode: status, Request: req, Header: http.Header{}},
+		Message:  message,
+	}
+}
+
+func notFound(method, path string) error {
+	return errorResponse(http.StatusNotFound, method, path, "Not Found")
+}
+
+type issuesService struct{ g *GitHub }
+
+func (s *issuesService) Get(_ context.Context, _ string, repo string, number int) (*github.Issue, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("repos/%s/%s/issues/%d", s.g.Owner, repo, number))
+	}
+	return clone(issue), ok(), nil
+}
+
+func (s *issuesService) Edit(_ context.Context, _ string, repo string, number int, req *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, nil, notFound("PATCH", fmt.Sprintf("repos/%s/%s/issues/%d", s.g.Owner, repo, number))
+	}
+	if req.Title != nil {
+		issue.Title = github.String(req.GetTitle())
+	}
+	if req.Body != nil {
+		issue.Body = github.String(req.GetBody())
+	}
+	if req.State != nil {
+		issue.State = github.String(req.GetState())
+	}
+	now := time.Now()
+	issue.UpdatedAt = &now
+	return clone(issue), ok(), nil
+}
+
+func (s *issuesService) ListByRepo(_ context.Context, _ string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if _, found := s.g.repos[repo]; !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("repos/%s/%s/issues", s.g.Owner, repo))
+	}
+	state := "open"
+	var listOpts *github.ListOptions
+	if opts != nil {
+		if opts.State != "" {
+			state = opts.State
+		}
+		listOpts = &opts.ListOptions
+	}
+	var issues []*github.Issue
+	for _, issue := range s.g.issues[repo] {
+		if state == "all" || issue.GetState() == state {
+			issues = append(issues, issue)
+		}
+	}
+	// GitHub lists the newest issues first by default
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].GetNumber() > issues[j].GetNumber()
+	})
+	issues, resp := paginate(issues, listOpts)
+	return clone(issues), resp, nil
+}
+
+func (s *issuesService) AddAssignees(_ context.Context, _ string, repo string, number int, assignees []string) (*github.Issue, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, nil, notFound("POST", fmt.Sprintf("repos/%s/%s/issues/%d/assignees", s.g.Owner, repo, number))
+	}
+	for _, login := range assignees {
+		assigned := false
+		for _, user := range issue.Assignees {
+			assigned = assigned || user.GetLogin() == login
+		}
+		if !assigned {
+			issue.Assignees = append(issue.Assignees, &github.User{Login: github.String(login)})
+		}
+	}
+	return clone(issue), ok(), nil
+}
+
+func (s *issuesService) RemoveAssignees(_ context.Context, _ string, repo string, number int, assignees []string) (*github.Issue, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, nil, notFound("DELETE", fmt.Sprintf("repos/%s/%s/issues/%d/assignees", s.g.Owner, repo, number))
+	}
+	var kept []*github.User
+	for _, user := range issue.Assignees {
+		removed := false
+		for _, login := range assignees {
+			removed = removed || user.GetLogin() == login
+		}
+		if !removed {
+			kept = append(kept, user)
+		}
+	}
+	issue.Assignees = kept
+	return clone(issue), ok(), nil
+}
+
+func (s *issuesService) AddLabelsToIssue(_ context.Context, _ string, repo string, number int, labels []string) ([]*github.Label, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, nil, notFound("POST", fmt.Sprintf("repos/%s/%s/issues/%d/labels", s.g.Owner, repo, number))
+	}
+	for _, name := range labels {
+		labelled := false
+		for _, label := range issue.Labels {
+			labelled = labelled || label.GetName() == name
+		}
+		if !labelled {
+			issue.Labels = append(issue.Labels, github.Label{Name: github.String(name)})
+		}
+	}
+	var result []*github.Label
+	for _, label := range clone(issue.Labels) {
+		label := label
+		result = append(result, &label)
+	}
+	return result, ok(), nil
+}
+
+func (s *issuesService) RemoveLabelForIssue(_ context.Context, _ string, repo string, number int, name string) (*github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/labels/%s", s.g.Owner, repo, number, name)
+	issue, found := s.g.issues[repo][number]
+	if !found {
+		return nil, notFound("DELETE", path)
+	}
+	for i, label := range issue.Labels {
+		if label.GetName() == name {
+			issue.Labels = append(issue.Labels[:i:i], issue.Labels[i+1:]...)
+			return ok(), nil
+		}
+	}
+	return nil, notFound("DELETE", path)
+}
+
+type organizationsService struct{ g *GitHub }
+
+func (s *organizationsService) ListProjects(_ context.Context, _ string, opts *github.ProjectListOptions) ([]*github.Project, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	state := "open"
+	var listOpts *github.ListOptions
+	if opts != nil {
+		if opts.State != "" {
+			state = opts.State
+		}
+		listOpts = &opts.ListOptions
+	}
+	var projects []*github.Project
+	for _, project := range s.g.projects {
+		if state == "all" || project.GetState() == state {
+			projects = append(projects, project)
+		}
+	}
+	projects, resp := paginate(projects, listOpts)
+	return clone(projects), resp, nil
+}
+
+type projectsService struct{ g *GitHub }
+
+func (s *projectsService) ListProjectColumns(_ context.Context, projectID int64, opts *github.ListOptions) ([]*github.ProjectColumn, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	columns, found := s.g.columns[projectID]
+	if !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("projects/%d/columns", projectID))
+	}
+	columns, resp := paginate(columns, opts)
+	return clone(columns), resp, nil
+}
+
+func (s *projectsService) findColumn(columnID int64) bool {
+	for _, columns := range s.g.columns {
+		for _, column := range columns {
+			if column.GetID() == columnID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *projectsService) ListProjectCards(_ context.Context, columnID int64, opts *github.ProjectCardListOptions) ([]*github.ProjectCard, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if !s.findColumn(columnID) {
+		return nil, nil, notFound("GET", fmt.Sprintf("projects/columns/%d/cards", columnID))
+	}
+	var listOpts *github.ListOptions
+	if opts != nil {
+		listOpts = &opts.ListOptions
+	}
+	cards, resp := paginate(s.g.cards[columnID], listOpts)
+	return clone(cards), resp, nil
+}
+
+func (s *projectsService) CreateProjectCard(_ context.Context, columnID int64, opts *github.ProjectCardOptions) (*github.ProjectCard, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	path := fmt.Sprintf("projects/columns/%d/cards", columnID)
+	if !s.findColumn(columnID) {
+		return nil, nil, notFound("POST", path)
+	}
+	card := &github.ProjectCard{
+		ID:       github.Int64(s.g.nextID()),
+		ColumnID: github.Int64(columnID),
+		Note:     github.String(opts.Note),
+	}
+	if opts.ContentID != 0 {
+		contentURL := s.contentURL(opts.ContentID)
+		if contentURL == "" {
+			return nil, nil, errorResponse(http.StatusUnprocessableEntity, "POST", path, "Validation Failed")
+		}
+		card.ContentURL = github.String(contentURL)
+	}
+	s.g.cards[columnID] = append(s.g.cards[columnID], card)
+	return clone(card), ok(), nil
+}
+
+// contentURL finds the issue URL of an issue or pull request from its ID.
+func (s *projectsService) contentURL(contentID int64) string {
+	for repo, pulls := range s.g.pulls {
+		for number, pr := range pulls {
+			if pr.GetID() == contentID {
+				return s.g.issues[repo][number].GetURL()
+			}
+		}
+	}
+	for _, issues := range s.g.issues {
+		for _, issue := range issues {
+			if issue.GetID() == contentID {
+				return issue.GetURL()
+			}
+		}
+	}
+	return ""
+}
+
+func (s *projectsService) MoveProjectCard(_ context.Context, cardID int64, opts *github.ProjectCardMoveOptions) (*github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	path := fmt.Sprintf("projects/columns/cards/%d/moves", cardID)
+	var card *github.ProjectCard
+	for columnID, cards := range s.g.cards {
+		for i, c := range cards {
+			if c.GetID() == cardID {
+				card = c
+				s.g.cards[columnID] = append(cards[:i:i], cards[i+1:]...)
+				break
+			}
+		}
+	}
+	if card == nil {
+		return nil, notFound("POST", path)
+	}
+	columnID := card.GetColumnID()
+	if opts.ColumnID != 0 {
+		if !s.findColumn(opts.ColumnID) {
+			return nil, errorResponse(http.StatusUnprocessableEntity, "POST", path, "Validation Failed")
+		}
+		columnID = opts.ColumnID
+	}
+	card.ColumnID = github.Int64(columnID)
+	cards := s.g.cards[columnID]
+	switch {
+	case opts.Position == "top":
+		s.g.cards[columnID] = append([]*github.ProjectCard{card}, cards...)
+	case strings.HasPrefix(opts.Position, "after:"):
+		after, _ := strconv.ParseInt(strings.TrimPrefix(opts.Position, "after:"), 10, 64)
+		i := 0
+		for i < len(cards) && cards[i].GetID() != after {
+			i++
+		}
+		if i < len(cards) {
+			i++
+		}
+		s.g.cards[columnID] = append(cards[:i:i], append([]*github.ProjectCard{card}, cards[i:]...)...)
+	default:
+		s.g.cards[columnID] = append(cards, card)
+	}
+	return ok(), nil
+}
+
+type projectsV2Service struct{ g *GitHub }
+
+func (s *projectsV2Service) project(projectID string) *matsuri.ProjectV2 {
+	for _, project := range s.g.projectsV2 {
+		if project.ID == projectID {
+			return project
+		}
+	}
+	return nil
+}
+
+func graphQLNotFound(id string) error {
+	return fmt.Errorf("GraphQL error: Could not resolve to a node with the global id of '%s'", id)
+}
+
+func (s *projectsV2Service) ListProjects(_ context.Context, _ string) (projects []*matsuri.ProjectV2, _ error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	return clone(s.g.projectsV2), nil
+}
+
+func (s *projectsV2Service) ListItems(_ context.Context, projectID string) (items []*matsuri.ProjectV2Item, _ error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if s.project(projectID) == nil {
+		return nil, graphQLNotFound(projectID)
+	}
+	for _, item := range s.g.items[projectID] {
+		items = append(items, clone(item))
+	}
+	return
+}
+
+func (s *projectsV2Service) GetItemForContent(_ context.Context, projectID, contentID string) (*matsuri.ProjectV2Item, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	for _, item := range s.g.items[projectID] {
+		if item.ContentID == contentID {
+			return clone(item), nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *projectsV2Service) AddItem(_ context.Context, projectID, contentID string) (*matsuri.ProjectV2Item, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if s.project(projectID) == nil {
+		return nil, graphQLNotFound(projectID)
+	}
+	// adding content that is already in the project returns the existing item
+	for _, item := range s.g.items[projectID] {
+		if item.ContentID == contentID {
+			return clone(item), nil
+		}
+	}
+	item := s.g.newItem(contentID)
+	if item == nil {
+		return nil, graphQLNotFound(contentID)
+	}
+	s.g.items[projectID] = append(s.g.items[projectID], item)
+	return clone(item), nil
+}
+
+func (s *projectsV2Service) SetItemStatus(_ context.Context, projectID, itemID, fieldID, optionID string) error {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	project := s.project(projectID)
+	if project == nil {
+		return graphQLNotFound(projectID)
+	}
+	if project.StatusField.ID != fieldID {
+		return graphQLNotFound(fieldID)
+	}
+	var status string
+	for _, option := range project.StatusField.Options {
+		if option.ID == optionID {
+			status = option.Name
+		}
+	}
+	if status == "" {
+		return fmt.Errorf("GraphQL error: The single select option Id does not belong to the field")
+	}
+	for _, item := range s.g.items[projectID] {
+		if item.ID == itemID {
+			item.Status = status
+			return nil
+		}
+	}
+	return graphQLNotFound(itemID)
+}
+
+type pullRequestsService struct{ g *GitHub }
+
+func (s *pullRequestsService) Create(_ context.Context, _ string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	path := fmt.Sprintf("repos/%s/%s/pulls", s.g.Owner, repo)
+	if _, found := s.g.repos[repo]; !found {
+		return nil, nil, notFound("POST", path)
+	}
+	for _, pr := range s.g.pulls[repo] {
+		if pr.GetState() == "open" && pr.GetHead().GetRef() == pull.GetHead() && pr.GetBase().GetRef() == pull.GetBase() {
+			return nil, nil, errorResponse(http.StatusUnprocessableEntity, "POST", path, "Validation Failed")
+		}
+	}
+	// pull requests share their numbering with issues
+	issue := s.g.addIssue(repo, pull.GetTitle())
+	issue.Body = github.String(pull.GetBody())
+	issue.NodeID = github.String(fmt.Sprintf("PR_%d", issue.GetID()))
+	issue.PullRequestLinks = &github.PullRequestLinks{
+		URL: github.String(fmt.Sprintf("%s/pulls/%d", s.g.repoURL(repo), issue.GetNumber())),
+	}
+	pr := &github.PullRequest{
+		ID:        github.Int64(s.g.nextID()),
+		NodeID:    issue.NodeID,
+		Number:    issue.Number,
+		Title:     github.String(pull.GetTitle()),
+		Body:      github.String(pull.GetBody()),
+		State:     github.String("open"),
+		HTMLURL:   github.String(fmt.Sprintf("https://github.com/%s/%s/pull/%d", s.g.Owner, repo, issue.GetNumber())),
+		Head:      &github.PullRequestBranch{Ref: github.String(pull.GetHead())},
+		Base:      &github.PullRequestBranch{Ref: github.String(pull.GetBase())},
+		CreatedAt: issue.CreatedAt,
+		UpdatedAt: issue.UpdatedAt,
+	}
+	s.g.pulls[repo][pr.GetNumber()] = pr
+	return clone(pr), ok(), nil
+}
+
+func (s *pullRequestsService) List(_ context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	if _, found := s.g.repos[repo]; !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("repos/%s/%s/pulls", s.g.Owner, repo))
+	}
+	state := "open"
+	var listOpts *github.ListOptions
+	var head, base string
+	if opts != nil {
+		if opts.State != "" {
+			state = opts.State
+		}
+		listOpts = &opts.ListOptions
+		head, base = opts.Head, opts.Base
+	}
+	var pulls []*github.PullRequest
+	for _, pr := range s.g.pulls[repo] {
+		if state != "all" && pr.GetState() != state {
+			continue
+		}
+		// the head is filtered as user:ref-name
+		if head != "" && head != owner+":"+pr.GetHead().GetRef() {
+			continue
+		}
+		if base != "" && base != pr.GetBase().GetRef() {
+			continue
+		}
+		pulls = append(pulls, pr)
+	}
+	// GitHub lists the newest pull requests first by default
+	sort.Slice(pulls, func(i, j int) bool {
+		return pulls[i].GetNumber() > pulls[j].GetNumber()
+	})
+	pulls, resp := paginate(pulls, listOpts)
+	return clone(pulls), resp, nil
+}
+
+func (s *pullRequestsService) Edit(_ context.Context, _ string, repo string, number int, pull *github.PullRequest) (*github.PullRequest, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	pr, found := s.g.pulls[repo][number]
+	if !found {
+		return nil, nil, notFound("PATCH", fmt.Sprintf("repos/%s/%s/pulls/%d", s.g.Owner, repo, number))
+	}
+	issue := s.g.issues[repo][number]
+	if pull.Title != nil {
+		pr.Title, issue.Title = github.String(pull.GetTitle()), github.String(pull.GetTitle())
+	}
+	if pull.Body != nil {
+		pr.Body, issue.Body = github.String(pull.GetBody()), github.String(pull.GetBody())
+	}
+	if pull.State != nil {
+		pr.State, issue.State = github.String(pull.GetState()), github.String(pull.GetState())
+	}
+	if pull.GetBase().GetRef() != "" {
+		pr.Base = &github.PullRequestBranch{Ref: github.String(pull.GetBase().GetRef())}
+	}
+	now := time.Now()
+	pr.UpdatedAt, issue.UpdatedAt = &now, &now
+	return clone(pr), ok(), nil
+}
+
+type rateLimitsService struct{}
+
+func (s *rateLimitsService) RateLimits(_ context.Context) (*github.RateLimits, *github.Response, error) {
+	reset := github.Timestamp{Time: time.Now().Add(time.Hour)}
+	return &github.RateLimits{
+		Core:   &github.Rate{Limit: 5000, Remaining: 5000, Reset: reset},
+		Search: &github.Rate{Limit: 30, Remaining: 30, Reset: reset},
+	}, ok(), nil
+}
+
+type repositoriesService struct{ g *GitHub }
+
+func (s *repositoriesService) Get(_ context.Context, _, repo string) (*github.Repository, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	r, found := s.g.repos[repo]
+	if !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("repos/%s/%s", s.g.Owner, repo))
+	}
+	return clone(r), ok(), nil
+}
+
+func (s *repositoriesService) GetLatestRelease(_ context.Context, _, repo string) (*github.RepositoryRelease, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	release, found := s.g.releases[repo]
+	if !found {
+		return nil, nil, notFound("GET", fmt.Sprintf("repos/%s/%s/releases/latest", s.g.Owner, repo))
+	}
+	return clone(release), ok(), nil
+}
+
+type usersService struct{ g *GitHub }
+
+func (s *usersService) Get(_ context.Context, user string) (*github.User, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	resp := ok()
+	if user == "" {
+		user = s.g.Login
+		if s.g.Scopes != nil {
+			resp.Header.Set("X-OAuth-Scopes", strings.Join(s.g.Scopes, ", "))
+		}
+	}
+	return &github.User{Login: github.String(user)}, resp, nil
+}
+
+func (s *usersService) ListEmails(_ context.Context, opts *github.ListOptions) ([]*github.UserEmail, *github.Response, error) {
+	s.g.mu.Lock()
+	defer s.g.mu.Unlock()
+	emails, resp := paginate(s.g.emails, opts)
+	return clone(emails), resp, nil
+}
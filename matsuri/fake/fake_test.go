@@ -0,0 +1,26 @@
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestReturnsCopies(t *testing.T) {
+	g := New()
+	g.AddRepo("web", "master")
+	g.AddIssue("web", "Fix the header").Title = github.String("Changed by the caller")
+
+	issue, _, err := g.Backend().Issues.Get(context.Background(), g.Owner, "web", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.GetTitle() != "Fix the header" {
+		t.Errorf("the issue returned by AddIssue shares its state with the fake: %q", issue.GetTitle())
+	}
+	issue.Labels = append(issue.Labels, github.Label{Name: github.String("bug")})
+	if labels := g.Issue("web", 1).Labels; len(labels) != 0 {
+		t.Errorf("the issue returned by the API shares its state with the fake: %v", labels)
+	}
+}
@@ -0,0 +1,109 @@
+package matsuri
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// Concurrency is the maximum number of issues fetched at the same time.
+var Concurrency = 8
+
+// FetchErrors collects the errors of the issues that could not be fetched.
+type FetchErrors []error
+
+// As finds the first of the individual errors matching target, so that errors.As can find a rate limit error among them.
+// Go 1.18 does not follow an Unwrap method returning several errors.
+func (e FetchErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports whether any of the individual errors matches target.
+func (e FetchErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e FetchErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("%d issue(s) could not be fetched:", len(e)))
+	for _, err := range e {
+		lines = append(lines, "  "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// issueRef identifies an issue by repository name and number.
+type issueRef struct {
+	repo   string
+	number int
+}
+
+// fetchIssues gets the referenced issues using at most Concurrency workers.
+// The issues are returned in the same order as refs; those that could not be fetched are left nil
+// and their errors are returned together as FetchErrors.
+func fetchIssues(refs []issueRef) ([]*github.Issue, error) {
+	client := GetClient()
+	issues := make([]*github.Issue, len(refs))
+	errs := make([]error, len(refs))
+
+	workers := Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(refs) {
+		workers = len(refs)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				issues[i], _, errs[i] = client.Issues.Get(ctx, owner(), refs[i].repo, refs[i].number)
+			}
+		}()
+	}
+	for i := range refs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var fetchErrs FetchErrors
+	for i, err := range errs {
+		if err != nil {
+			issues[i] = nil
+			fetchErrs = append(fetchErrs, fmt.Errorf("%s#%d: %w", refs[i].repo, refs[i].number, err))
+		}
+	}
+	if len(fetchErrs) != 0 {
+		return issues, fetchErrs
+	}
+	return issues, nil
+}
+
+// fetchCardIssues gets the issues of the cards, in order. See fetchIssues.
+func fetchCardIssues(cards []*Card) ([]*github.Issue, error) {
+	refs := make([]issueRef, len(cards))
+	for i, card := range cards {
+		refs[i] = issueRef{
+			repo:   card.Repo,
+			number: card.Number,
+		}
+	}
+	return fetchIssues(refs)
+}
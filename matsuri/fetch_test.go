@@ -0,0 +1,105 @@
+package matsuri
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// countingIssues serves issues whose number is not in missing, and records the highest number of concurrent requests.
+type countingIssues struct {
+	IssuesService
+	missing map[int]bool
+
+	mu     sync.Mutex
+	active int
+	peak   int
+}
+
+func (s *countingIssues) Get(_ context.Context, _ string, repo string, number int) (*github.Issue, *github.Response, error) {
+	s.mu.Lock()
+	s.active++
+	if s.active > s.peak {
+		s.peak = s.active
+	}
+	s.mu.Unlock()
+	// give the other workers a chance to run at the same time
+	time.Sleep(time.Millisecond)
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+	if s.missing[number] {
+		return nil, nil, errors.New("not found")
+	}
+	return &github.Issue{Number: github.Int(number), Title: github.String(repo)}, nil, nil
+}
+
+func TestFetchIssues(t *testing.T) {
+	defer func(concurrency int) { Concurrency = concurrency }(Concurrency)
+	tests := []struct {
+		name        string
+		concurrency int
+		refs        int
+		missing     map[int]bool
+		wantPeak    int
+	}{
+		{name: "no issue", concurrency: 4, refs: 0},
+		{name: "fewer issues than workers", concurrency: 8, refs: 3, wantPeak: 3},
+		{name: "bounded", concurrency: 4, refs: 40, wantPeak: 4},
+		{name: "sequential", concurrency: 1, refs: 5, wantPeak: 1},
+		{name: "invalid concurrency", concurrency: 0, refs: 5, wantPeak: 1},
+		{name: "missing issues", concurrency: 4, refs: 10, missing: map[int]bool{3: true, 7: true}, wantPeak: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Concurrency = tt.concurrency
+			service := &countingIssues{missing: tt.missing}
+			SetBackend(&Backend{Issues: service})
+			var refs []issueRef
+			for i := 1; i <= tt.refs; i++ {
+				refs = append(refs, issueRef{repo: fmt.Sprintf("repo%d", i), number: i})
+			}
+
+			issues, err := fetchIssues(refs)
+			if len(issues) != tt.refs {
+				t.Fatalf("fetchIssues() returned %d issues, want %d", len(issues), tt.refs)
+			}
+			for i, issue := range issues {
+				number := i + 1
+				if tt.missing[number] {
+					if issue != nil {
+						t.Errorf("the missing issue %d was returned", number)
+					}
+					continue
+				}
+				if issue.GetNumber() != number || issue.GetTitle() != refs[i].repo {
+					t.Errorf("issue %d is %d of %s", number, issue.GetNumber(), issue.GetTitle())
+				}
+			}
+			var fetchErrs FetchErrors
+			if errors.As(err, &fetchErrs) != (len(tt.missing) != 0) || len(fetchErrs) != len(tt.missing) {
+				t.Errorf("fetchIssues() error = %v, want %d missing issues", err, len(tt.missing))
+			}
+			// the peak may stay below the limit if the workers happen not to overlap, but never above it
+			if service.peak > tt.wantPeak || tt.refs > 0 && service.peak == 0 {
+				t.Errorf("%d requests ran at the same time, want at most %d", service.peak, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestFetchErrorsIs(t *testing.T) {
+	target := errors.New("target")
+	errs := FetchErrors{errors.New("other"), fmt.Errorf("wrapped: %w", target)}
+	if !errors.Is(errs, target) {
+		t.Error("errors.Is() does not find an error inside FetchErrors")
+	}
+	if errors.Is(FetchErrors{errors.New("other")}, target) {
+		t.Error("errors.Is() finds an error that is not inside FetchErrors")
+	}
+}
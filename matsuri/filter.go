@@ -0,0 +1,170 @@
+package matsuri
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// Sort orders and groupings of todo.
+const (
+	SortCreated = "created"
+	SortUpdated = "updated"
+	SortNumber  = "number"
+
+	GroupByRepo     = "repo"
+	GroupByLabel    = "label"
+	GroupByAssignee = "assignee"
+)
+
+// IssueFilter selects issues. Empty fields match every issue, and text is compared regardless of case.
+type IssueFilter struct {
+	// Labels must all be on the issue.
+	Labels []string
+	// Assignee must be one of the assignees of the issue.
+	Assignee string
+	// Unassigned selects issues without assignees.
+	Unassigned bool
+	Milestone  string
+	Repo       string
+	// Title is searched for in the title of the issue.
+	Title string
+}
+
+// Empty reports whether the filter matches every issue.
+func (f *IssueFilter) Empty() bool {
+	return len(f.Labels) == 0 && f.Assignee == "" && !f.Unassigned && f.Milestone == "" && f.Repo == "" && f.Title == ""
+}
+
+// Match reports whether the issue passes the filter.
+func (f *IssueFilter) Match(issue *github.Issue) bool {
+	for _, wanted := range f.Labels {
+		found := false
+		for _, label := range issue.Labels {
+			found = found || strings.EqualFold(label.GetName(), wanted)
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Assignee != "" {
+		found := false
+		for _, assignee := range issue.Assignees {
+			found = found || strings.EqualFold(assignee.GetLogin(), f.Assignee)
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Unassigned && len(issue.Assignees) != 0 {
+		return false
+	}
+	if f.Milestone != "" && !strings.EqualFold(issue.GetMilestone().GetTitle(), f.Milestone) {
+		return false
+	}
+	if f.Repo != "" {
+		// issues of other owners do not resolve to a repository
+		repoName := GetRepoNameFromURL(issue.GetRepositoryURL())
+		if repoName == "" || !strings.EqualFold(repoName, f.Repo) {
+			return false
+		}
+	}
+	if f.Title != "" && !strings.Contains(strings.ToLower(issue.GetTitle()), strings.ToLower(f.Title)) {
+		return false
+	}
+	return true
+}
+
+// FilterIssues keeps the issues passing the filter.
+func FilterIssues(issues []*github.Issue, f *IssueFilter) (filtered []*github.Issue) {
+	for _, issue := range issues {
+		if issue != nil && f.Match(issue) {
+			filtered = append(filtered, issue)
+		}
+	}
+	return
+}
+
+// ValidateSort checks that issues can be sorted in the given order.
+func ValidateSort(by string) error {
+	switch by {
+	case "", SortCreated, SortUpdated, SortNumber:
+		return nil
+	}
+	return fmt.Errorf("invalid sort order %q, use one of: %s, %s, %s", by, SortCreated, SortUpdated, SortNumber)
+}
+
+// SortIssues orders issues by number, or from the most recently created or updated. An empty order keeps them as is.
+func SortIssues(issues []*github.Issue, by string) error {
+	if err := ValidateSort(by); err != nil {
+		return err
+	}
+	var less func(a, b *github.Issue) bool
+	switch by {
+	case "":
+		return nil
+	case SortNumber:
+		less = func(a, b *github.Issue) bool { return a.GetNumber() < b.GetNumber() }
+	case SortCreated:
+		less = func(a, b *github.Issue) bool { return a.GetCreatedAt().After(b.GetCreatedAt()) }
+	case SortUpdated:
+		less = func(a, b *github.Issue) bool { return a.GetUpdatedAt().After(b.GetUpdatedAt()) }
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return less(issues[i], issues[j])
+	})
+	return nil
+}
+
+// ValidateGroupBy checks that issues can be grouped by the given field.
+func ValidateGroupBy(by string) error {
+	switch by {
+	case "", GroupByRepo, GroupByLabel, GroupByAssignee:
+		return nil
+	}
+	return fmt.Errorf("invalid grouping %q, use one of: %s, %s, %s", by, GroupByRepo, GroupByLabel, GroupByAssignee)
+}
+
+// issueGroup is a heading of a grouped report along with its issues.
+type issueGroup struct {
+	name   string
+	issues []*ReportIssue
+}
+
+// groupIssues groups issues by repository, label or assignee, in the order the groups first appear.
+// Issues with several labels or assignees appear in each of their groups, and those without any in a "(none)" group.
+func groupIssues(issues []*ReportIssue, by string) (groups []*issueGroup) {
+	if by == "" {
+		return []*issueGroup{{issues: issues}}
+	}
+	byName := map[string]*issueGroup{}
+	add := func(name string, issue *ReportIssue) {
+		group, found := byName[name]
+		if !found {
+			group = &issueGroup{name: name}
+			byName[name] = group
+			groups = append(groups, group)
+		}
+		group.issues = append(group.issues, issue)
+	}
+	for _, issue := range issues {
+		var names []string
+		switch by {
+		case GroupByRepo:
+			names = []string{issue.Repository}
+		case GroupByLabel:
+			names = issue.Labels
+		case GroupByAssignee:
+			names = issue.Assignees
+		}
+		if len(names) == 0 {
+			names = []string{"(none)"}
+		}
+		for _, name := range names {
+			add(name, issue)
+		}
+	}
+	return
+}
@@ -0,0 +1,148 @@
+package matsuri
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// numbersOf gets the numbers of issues.
+func numbersOf(issues []*github.Issue) (numbers []int) {
+	for _, issue := range issues {
+		numbers = append(numbers, issue.GetNumber())
+	}
+	return
+}
+
+func TestFilterIssues(t *testing.T) {
+	milestone := testIssue(5, "api", "Ship it", nil, nil)
+	milestone.Milestone = &github.Milestone{Title: github.String("2024")}
+	// the repository of an issue of another owner cannot be told
+	other := testIssue(6, "web", "Elsewhere", nil, nil)
+	other.RepositoryURL = github.String("https://api.github.com/repos/someone/web")
+	issues := []*github.Issue{
+		testIssue(1, "web", "Fix the header", []string{"bug", "web"}, []string{"alice"}),
+		testIssue(2, "web", "Add a footer", []string{"feature"}, []string{"bob", "alice"}),
+		testIssue(3, "api", "Fix the login", []string{"Bug"}, nil),
+		nil,
+		testIssue(4, "api", "Document the API", nil, []string{"Bob"}),
+		milestone,
+		other,
+	}
+	tests := []struct {
+		name      string
+		filter    IssueFilter
+		want      []int
+		wantEmpty bool
+	}{
+		{name: "empty", filter: IssueFilter{}, want: []int{1, 2, 3, 4, 5, 6}, wantEmpty: true},
+		{name: "label regardless of case", filter: IssueFilter{Labels: []string{"BUG"}}, want: []int{1, 3}},
+		{name: "every label", filter: IssueFilter{Labels: []string{"bug", "web"}}, want: []int{1}},
+		{name: "assignee", filter: IssueFilter{Assignee: "bob"}, want: []int{2, 4}},
+		{name: "unassigned", filter: IssueFilter{Unassigned: true}, want: []int{3, 5, 6}},
+		{name: "milestone", filter: IssueFilter{Milestone: "2024"}, want: []int{5}},
+		{name: "repository", filter: IssueFilter{Repo: "WEB"}, want: []int{1, 2}},
+		{name: "title", filter: IssueFilter{Title: "fix"}, want: []int{1, 3}},
+		{name: "combined", filter: IssueFilter{Repo: "web", Assignee: "alice", Title: "footer"}, want: []int{2}},
+		{name: "nothing", filter: IssueFilter{Labels: []string{"wontfix"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Empty(); got != tt.wantEmpty {
+				t.Errorf("Empty() = %v, want %v", got, tt.wantEmpty)
+			}
+			if got := numbersOf(FilterIssues(issues, &tt.filter)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterIssues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortIssues(t *testing.T) {
+	day := func(d int) *time.Time {
+		date := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	tests := []struct {
+		by      string
+		want    []int
+		wantErr bool
+	}{
+		{by: "", want: []int{2, 3, 1}},
+		{by: SortNumber, want: []int{1, 2, 3}},
+		{by: SortCreated, want: []int{3, 2, 1}},
+		{by: SortUpdated, want: []int{1, 3, 2}},
+		{by: "title", want: []int{2, 3, 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		issues := []*github.Issue{
+			{Number: github.Int(2), CreatedAt: day(2), UpdatedAt: day(2)},
+			{Number: github.Int(3), CreatedAt: day(3), UpdatedAt: day(3)},
+			{Number: github.Int(1), CreatedAt: day(1), UpdatedAt: day(4)},
+		}
+		err := SortIssues(issues, tt.by)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("SortIssues(%q) error = %v", tt.by, err)
+		}
+		if got := numbersOf(issues); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SortIssues(%q) = %v, want %v", tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	for _, by := range []string{"", SortCreated, SortUpdated, SortNumber} {
+		if err := ValidateSort(by); err != nil {
+			t.Errorf("ValidateSort(%q) error = %v", by, err)
+		}
+	}
+	if err := ValidateSort("title"); err == nil {
+		t.Error("ValidateSort() accepted title")
+	}
+}
+
+func TestValidateGroupBy(t *testing.T) {
+	for _, by := range []string{"", GroupByRepo, GroupByLabel, GroupByAssignee} {
+		if err := ValidateGroupBy(by); err != nil {
+			t.Errorf("ValidateGroupBy(%q) error = %v", by, err)
+		}
+	}
+	if err := ValidateGroupBy("milestone"); err == nil {
+		t.Error("ValidateGroupBy() accepted milestone")
+	}
+}
+
+func TestGroupIssues(t *testing.T) {
+	issues := []*ReportIssue{
+		{Number: 1, Repository: "web", Labels: []string{"bug", "web"}, Assignees: []string{"alice"}},
+		{Number: 2, Repository: "api", Labels: []string{"bug"}},
+		{Number: 3, Repository: "web", Assignees: []string{"bob", "alice"}},
+	}
+	tests := []struct {
+		by string
+		// want lists the groups as "name: numbers"
+		want []string
+	}{
+		{by: "", want: []string{": 1 2 3"}},
+		{by: GroupByRepo, want: []string{"web: 1 3", "api: 2"}},
+		{by: GroupByLabel, want: []string{"bug: 1 2", "web: 1", "(none): 3"}},
+		{by: GroupByAssignee, want: []string{"alice: 1 3", "(none): 2", "bob: 3"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, group := range groupIssues(issues, tt.by) {
+			var numbers []string
+			for _, issue := range group.issues {
+				numbers = append(numbers, strconv.Itoa(issue.Number))
+			}
+			got = append(got, group.name+": "+strings.Join(numbers, " "))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("groupIssues(%q) = %q, want %q", tt.by, got, tt.want)
+		}
+	}
+}
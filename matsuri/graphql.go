@@ -0,0 +1,273 @@
+package matsuri
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// graphQLError is an error reported by the GitHub GraphQL API.
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// graphQLClient sends GraphQL queries using the authentication and base URL of a REST client.
+type graphQLClient struct {
+	client *github.Client
+}
+
+// url gets the GraphQL endpoint matching the REST base URL: api.github.com/graphql, or /api/graphql for GitHub Enterprise.
+func (c *graphQLClient) url() string {
+	base := c.client.BaseURL.String()
+	if strings.HasSuffix(base, "/api/v3/") {
+		return strings.TrimSuffix(base, "v3/") + "graphql"
+	}
+	return base + "graphql"
+}
+
+// query runs a GraphQL query or mutation and decodes its data into result.
+func (c *graphQLClient) query(ctx context.Context, query string, variables map[string]interface{}, result interface{}) (err error) {
+	body := map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	}
+	req, err := c.client.NewRequest("POST", c.url(), body)
+	if err != nil {
+		return
+	}
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if _, err = c.client.Do(ctx, req, &resp); err != nil {
+		return
+	}
+	if len(resp.Errors) != 0 {
+		messages := make([]string, len(resp.Errors))
+		for i, e := range resp.Errors {
+			messages[i] = e.Message
+		}
+		err = fmt.Errorf("GraphQL error: %s", strings.Join(messages, "; "))
+		return
+	}
+	if len(resp.Data) == 0 {
+		err = errors.New("GraphQL error: empty response")
+		return
+	}
+	return json.Unmarshal(resp.Data, result)
+}
+
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// projectV2Item is the GraphQL shape of a project item, shared by the queries below.
+type projectV2Item struct {
+	ID      string `json:"id"`
+	Project struct {
+		ID string `json:"id"`
+	} `json:"project"`
+	Status *struct {
+		Name     string `json:"name"`
+		OptionID string `json:"optionId"`
+	} `json:"status"`
+	Content struct {
+		Typename   string `json:"__typename"`
+		ID         string `json:"id"`
+		Number     int    `json:"number"`
+		Title      string `json:"title"`
+		Repository struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+	} `json:"content"`
+}
+
+const projectV2ItemFields = `
+	id
+	project { id }
+	status: fieldValueByName(name: "Status") {
+		... on ProjectV2ItemFieldSingleSelectValue { name optionId }
+	}
+	content {
+		__typename
+		... on Issue { id number title repository { name } }
+		... on PullRequest { id number title repository { name } }
+		... on DraftIssue { id title }
+	}`
+
+func (i *projectV2Item) toItem() *ProjectV2Item {
+	item := &ProjectV2Item{
+		ID:          i.ID,
+		ContentType: i.Content.Typename,
+		ContentID:   i.Content.ID,
+		Number:      i.Content.Number,
+		Repository:  i.Content.Repository.Name,
+		Title:       i.Content.Title,
+	}
+	if i.Status != nil {
+		item.Status = i.Status.Name
+	}
+	return item
+}
+
+// projectsV2Service implements ProjectsV2Service with the GitHub GraphQL API.
+type projectsV2Service struct {
+	gql *graphQLClient
+}
+
+const listProjectsV2Query = `query($org: String!, $cursor: String) {
+	organization(login: $org) {
+		projectsV2(first: 100, after: $cursor) {
+			pageInfo { hasNextPage endCursor }
+			nodes {
+				id
+				number
+				title
+				closed
+				status: field(name: "Status") {
+					... on ProjectV2SingleSelectField { id name options { id name } }
+				}
+			}
+		}
+	}
+}`
+
+func (s *projectsV2Service) ListProjects(ctx context.Context, org string) (projects []*ProjectV2, err error) {
+	variables := map[string]interface{}{"org": org, "cursor": nil}
+	for {
+		var data struct {
+			Organization struct {
+				ProjectsV2 struct {
+					PageInfo pageInfo `json:"pageInfo"`
+					Nodes    []struct {
+						ID     string                      `json:"id"`
+						Number int                         `json:"number"`
+						Title  string                      `json:"title"`
+						Closed bool                        `json:"closed"`
+						Status *ProjectV2SingleSelectField `json:"status"`
+					} `json:"nodes"`
+				} `json:"projectsV2"`
+			} `json:"organization"`
+		}
+		if err = s.gql.query(ctx, listProjectsV2Query, variables, &data); err != nil {
+			return
+		}
+		for _, node := range data.Organization.ProjectsV2.Nodes {
+			projects = append(projects, &ProjectV2{
+				ID:          node.ID,
+				Number:      node.Number,
+				Title:       node.Title,
+				Closed:      node.Closed,
+				StatusField: node.Status,
+			})
+		}
+		page := data.Organization.ProjectsV2.PageInfo
+		if !page.HasNextPage {
+			return
+		}
+		variables["cursor"] = page.EndCursor
+	}
+}
+
+const listProjectV2ItemsQuery = `query($project: ID!, $cursor: String) {
+	node(id: $project) {
+		... on ProjectV2 {
+			items(first: 100, after: $cursor) {
+				pageInfo { hasNextPage endCursor }
+				nodes {` + projectV2ItemFields + `
+				}
+			}
+		}
+	}
+}`
+
+func (s *projectsV2Service) ListItems(ctx context.Context, projectID string) (items []*ProjectV2Item, err error) {
+	variables := map[string]interface{}{"project": projectID, "cursor": nil}
+	for {
+		var data struct {
+			Node struct {
+				Items struct {
+					PageInfo pageInfo        `json:"pageInfo"`
+					Nodes    []projectV2Item `json:"nodes"`
+				} `json:"items"`
+			} `json:"node"`
+		}
+		if err = s.gql.query(ctx, listProjectV2ItemsQuery, variables, &data); err != nil {
+			return
+		}
+		for i := range data.Node.Items.Nodes {
+			items = append(items, data.Node.Items.Nodes[i].toItem())
+		}
+		page := data.Node.Items.PageInfo
+		if !page.HasNextPage {
+			return
+		}
+		variables["cursor"] = page.EndCursor
+	}
+}
+
+const getProjectV2ItemForContentQuery = `query($content: ID!) {
+	node(id: $content) {
+		... on Issue { projectItems(first: 100) { nodes {` + projectV2ItemFields + ` } } }
+		... on PullRequest { projectItems(first: 100) { nodes {` + projectV2ItemFields + ` } } }
+	}
+}`
+
+func (s *projectsV2Service) GetItemForContent(ctx context.Context, projectID, contentID string) (item *ProjectV2Item, err error) {
+	var data struct {
+		Node struct {
+			ProjectItems struct {
+				Nodes []projectV2Item `json:"nodes"`
+			} `json:"projectItems"`
+		} `json:"node"`
+	}
+	err = s.gql.query(ctx, getProjectV2ItemForContentQuery, map[string]interface{}{"content": contentID}, &data)
+	if err != nil {
+		return
+	}
+	for i := range data.Node.ProjectItems.Nodes {
+		if node := data.Node.ProjectItems.Nodes[i]; node.Project.ID == projectID {
+			item = node.toItem()
+			return
+		}
+	}
+	return
+}
+
+const addProjectV2ItemMutation = `mutation($project: ID!, $content: ID!) {
+	addProjectV2ItemById(input: {projectId: $project, contentId: $content}) {
+		item {` + projectV2ItemFields + `
+		}
+	}
+}`
+
+func (s *projectsV2Service) AddItem(ctx context.Context, projectID, contentID string) (item *ProjectV2Item, err error) {
+	var data struct {
+		AddProjectV2ItemByID struct {
+			Item projectV2Item `json:"item"`
+		} `json:"addProjectV2ItemById"`
+	}
+	variables := map[string]interface{}{"project": projectID, "content": contentID}
+	if err = s.gql.query(ctx, addProjectV2ItemMutation, variables, &data); err != nil {
+		return
+	}
+	item = data.AddProjectV2ItemByID.Item.toItem()
+	return
+}
+
+const setProjectV2ItemStatusMutation = `mutation($project: ID!, $item: ID!, $field: ID!, $option: String!) {
+	updateProjectV2ItemFieldValue(input: {projectId: $project, itemId: $item, fieldId: $field, value: {singleSelectOptionId: $option}}) {
+		projectV2Item { id }
+	}
+}`
+
+func (s *projectsV2Service) SetItemStatus(ctx context.Context, projectID, itemID, fieldID, optionID string) error {
+	var data struct{}
+	variables := map[string]interface{}{"project": projectID, "item": itemID, "field": fieldID, "option": optionID}
+	return s.gql.query(ctx, setProjectV2ItemStatusMutation, variables, &data)
+}
@@ -0,0 +1,312 @@
+package matsuri
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// graphQLRequest is a query or mutation received by graphQLServer.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLServer answers the queries and mutations of projectsV2Service for a board with the projects Matsuri 2023,
+// closed, and Matsuri 2024, whose Status field has the options To do, In progress and Done.
+// It also serves the REST issues of web, whose node IDs are used to look up their items.
+type graphQLServer struct {
+	mu       sync.Mutex
+	requests []graphQLRequest
+	// errors makes every query fail with these messages when set
+	errors []string
+}
+
+const (
+	testProjectV2Items = `[
+		{"id": "PVTI_1", "project": {"id": "PVT_2024"}, "status": {"name": "To do", "optionId": "opt_todo"},
+			"content": {"__typename": "Issue", "id": "I_1", "number": 1, "title": "Fix the header", "repository": {"name": "web"}}},
+		{"id": "PVTI_2", "project": {"id": "PVT_2024"}, "status": {"name": "In progress", "optionId": "opt_doing"},
+			"content": {"__typename": "PullRequest", "id": "PR_2", "number": 2, "title": "ISSUE-1: Fix the header", "repository": {"name": "web"}}},
+		{"id": "PVTI_3", "project": {"id": "PVT_2024"}, "status": {"name": "To do", "optionId": "opt_todo"},
+			"content": {"__typename": "DraftIssue", "id": "DI_3", "title": "Book the venue"}},
+		{"id": "PVTI_4", "project": {"id": "PVT_2024"}, "status": null,
+			"content": {"__typename": "Issue", "id": "I_4", "number": 4, "title": "Triage me", "repository": {"name": "web"}}}
+	]`
+	testProjectV2Status = `{"id": "PVTSSF_status", "name": "Status", "options": [
+		{"id": "opt_todo", "name": "To do"}, {"id": "opt_doing", "name": "In progress"}, {"id": "opt_done", "name": "Done"}]}`
+)
+
+func (s *graphQLServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/repos/MatsuriJapon/web/issues/") {
+		number := strings.TrimPrefix(r.URL.Path, "/repos/MatsuriJapon/web/issues/")
+		fmt.Fprintf(w, `{"number": %s, "node_id": "I_%s"}`, number, number)
+		return
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+		http.NotFound(w, r)
+		return
+	}
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	failures := s.errors
+	s.mu.Unlock()
+	if len(failures) != 0 {
+		var resp struct {
+			Errors []graphQLError `json:"errors"`
+		}
+		for _, message := range failures {
+			resp.Errors = append(resp.Errors, graphQLError{Message: message})
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+		return
+	}
+	switch {
+	case strings.Contains(req.Query, "projectsV2("):
+		// the newest project comes first, on a page of its own
+		if req.Variables["cursor"] == nil {
+			fmt.Fprintf(w, `{"data": {"organization": {"projectsV2": {"pageInfo": {"hasNextPage": true, "endCursor": "page2"},
+				"nodes": [{"id": "PVT_2024", "number": 2, "title": "Matsuri 2024", "closed": false, "status": %s}]}}}}`, testProjectV2Status)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"organization": {"projectsV2": {"pageInfo": {"hasNextPage": false, "endCursor": "page3"},
+			"nodes": [{"id": "PVT_2023", "number": 1, "title": "Matsuri 2023", "closed": true, "status": null}]}}}}`)
+	case strings.Contains(req.Query, "items(first"):
+		fmt.Fprintf(w, `{"data": {"node": {"items": {"pageInfo": {"hasNextPage": false, "endCursor": ""}, "nodes": %s}}}}`, testProjectV2Items)
+	case strings.Contains(req.Query, "projectItems(first"):
+		if req.Variables["content"] != "I_1" {
+			fmt.Fprint(w, `{"data": {"node": {"projectItems": {"nodes": []}}}}`)
+			return
+		}
+		// the issue is on both boards, the item of the other one comes first
+		fmt.Fprint(w, `{"data": {"node": {"projectItems": {"nodes": [
+			{"id": "PVTI_old", "project": {"id": "PVT_2023"}, "status": {"name": "Done", "optionId": "opt_old"},
+				"content": {"__typename": "Issue", "id": "I_1", "number": 1, "title": "Fix the header", "repository": {"name": "web"}}},
+			{"id": "PVTI_1", "project": {"id": "PVT_2024"}, "status": {"name": "To do", "optionId": "opt_todo"},
+				"content": {"__typename": "Issue", "id": "I_1", "number": 1, "title": "Fix the header", "repository": {"name": "web"}}}
+		]}}}}`)
+	case strings.Contains(req.Query, "addProjectV2ItemById"):
+		fmt.Fprintf(w, `{"data": {"addProjectV2ItemById": {"item": {"id": "PVTI_new", "project": {"id": %q}, "status": null,
+			"content": {"__typename": "PullRequest", "id": %q, "number": 5, "title": "ISSUE-4: Triage me", "repository": {"name": "web"}}}}}}`,
+			req.Variables["project"], req.Variables["content"])
+	case strings.Contains(req.Query, "updateProjectV2ItemFieldValue"):
+		fmt.Fprintf(w, `{"data": {"updateProjectV2ItemFieldValue": {"projectV2Item": {"id": %q}}}}`, req.Variables["item"])
+	default:
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+	}
+}
+
+// mutations returns the variables of the mutations received, in order.
+func (s *graphQLServer) mutations() (variables []map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, req := range s.requests {
+		if strings.HasPrefix(strings.TrimSpace(req.Query), "mutation") {
+			variables = append(variables, req.Variables)
+		}
+	}
+	return
+}
+
+// cursors returns the cursor variables of the queries received, in order.
+func (s *graphQLServer) cursors() (cursors []interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, req := range s.requests {
+		if cursor, ok := req.Variables["cursor"]; ok {
+			cursors = append(cursors, cursor)
+		}
+	}
+	return
+}
+
+// newGraphQLTestBackend starts a graphQLServer and returns a Backend using it.
+func newGraphQLTestBackend(t *testing.T) (*graphQLServer, *Backend) {
+	t.Helper()
+	server := &graphQLServer{}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	b, err := NewGitHubBackend(ts.Client(), ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return server, b
+}
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		base string
+		want string
+	}{
+		{base: "https://api.github.com/", want: "https://api.github.com/graphql"},
+		{base: "https://github.example.com/api/v3/", want: "https://github.example.com/api/graphql"},
+	}
+	for _, tt := range tests {
+		b, err := NewGitHubBackend(nil, tt.base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := b.ProjectsV2.(*projectsV2Service).gql.url(); got != tt.want {
+			t.Errorf("url() for %s = %s, want %s", tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestProjectsV2Service(t *testing.T) {
+	server, b := newGraphQLTestBackend(t)
+	ctx := context.Background()
+
+	projects, err := b.ProjectsV2.ListProjects(ctx, "MatsuriJapon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Title != "Matsuri 2024" || projects[1].Title != "Matsuri 2023" || !projects[1].Closed {
+		t.Fatalf("ListProjects() = %+v", projects)
+	}
+	if field := projects[0].StatusField; field == nil || field.ID != "PVTSSF_status" || len(field.Options) != 3 || field.Options[1].Name != "In progress" {
+		t.Errorf("the Status field is %+v", field)
+	}
+	if cursors := server.cursors(); !reflect.DeepEqual(cursors, []interface{}{nil, "page2"}) {
+		t.Errorf("the projects were listed with the cursors %v", cursors)
+	}
+
+	items, err := b.ProjectsV2.ListItems(ctx, "PVT_2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*ProjectV2Item{
+		{ID: "PVTI_1", Status: "To do", ContentType: "Issue", ContentID: "I_1", Number: 1, Repository: "web", Title: "Fix the header"},
+		{ID: "PVTI_2", Status: "In progress", ContentType: "PullRequest", ContentID: "PR_2", Number: 2, Repository: "web", Title: "ISSUE-1: Fix the header"},
+		{ID: "PVTI_3", Status: "To do", ContentType: "DraftIssue", ContentID: "DI_3", Title: "Book the venue"},
+		{ID: "PVTI_4", ContentType: "Issue", ContentID: "I_4", Number: 4, Repository: "web", Title: "Triage me"},
+	}
+	if !reflect.DeepEqual(items, want) {
+		for _, item := range items {
+			t.Logf("%+v", item)
+		}
+		t.Errorf("ListItems() did not convert the items")
+	}
+
+	item, err := b.ProjectsV2.GetItemForContent(ctx, "PVT_2024", "I_1")
+	if err != nil || item == nil || item.ID != "PVTI_1" || item.Status != "To do" {
+		t.Errorf("GetItemForContent() = %+v, %v, want the item of the project", item, err)
+	}
+	if item, err = b.ProjectsV2.GetItemForContent(ctx, "PVT_2024", "I_9"); err != nil || item != nil {
+		t.Errorf("GetItemForContent() = %+v, %v for an issue outside of the project", item, err)
+	}
+
+	if item, err = b.ProjectsV2.AddItem(ctx, "PVT_2024", "PR_5"); err != nil || item.ID != "PVTI_new" || item.ContentID != "PR_5" {
+		t.Errorf("AddItem() = %+v, %v", item, err)
+	}
+	if err = b.ProjectsV2.SetItemStatus(ctx, "PVT_2024", "PVTI_1", "PVTSSF_status", "opt_doing"); err != nil {
+		t.Fatal(err)
+	}
+	wantMutations := []map[string]interface{}{
+		{"project": "PVT_2024", "content": "PR_5"},
+		{"project": "PVT_2024", "item": "PVTI_1", "field": "PVTSSF_status", "option": "opt_doing"},
+	}
+	if mutations := server.mutations(); !reflect.DeepEqual(mutations, wantMutations) {
+		t.Errorf("the mutations sent are %v, want %v", mutations, wantMutations)
+	}
+}
+
+func TestProjectsV2ServiceErrors(t *testing.T) {
+	server, b := newGraphQLTestBackend(t)
+	server.errors = []string{"Could not resolve to an Organization", "Something went wrong"}
+	_, err := b.ProjectsV2.ListProjects(context.Background(), "MatsuriJapon")
+	if err == nil || err.Error() != "GraphQL error: Could not resolve to an Organization; Something went wrong" {
+		t.Errorf("ListProjects() error = %v", err)
+	}
+}
+
+func TestV2Board(t *testing.T) {
+	server, b := newGraphQLTestBackend(t)
+	defer SetBackend(backend)
+	SetBackend(b)
+	defer SetConfig(DefaultConfig())
+	if err := SetConfig(DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the closed project of 2023 is older, but only open projects are used by default
+	project, err := GetProject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Name != "Matsuri 2024" {
+		t.Fatalf("GetProject() = %s", project.Name)
+	}
+	columns, err := getBoard().columns(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"To do", "In progress", "Done"}) {
+		t.Errorf("the columns are %v, want the options of the Status field", names)
+	}
+
+	todo, err := GetProjectColumnByName(project, "To do")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards, err := getBoard().cards(todo, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the draft issue has no repository or number, and the item without a status is in no column
+	if len(cards) != 2 || cards[0].Repo != "web" || cards[0].Number != 1 || cards[1].Repo != "" || cards[1].Number != 0 {
+		t.Errorf("the cards of To do are %+v, %+v", cards[0], cards[1])
+	}
+
+	item, err := v2Board{}.findItem(project, "web", 1)
+	if err != nil || item == nil || item.ID != "PVTI_1" {
+		t.Fatalf("findItem() = %+v, %v", item, err)
+	}
+	if item, err = (v2Board{}).findItem(project, "web", 9); err != nil || item != nil {
+		t.Errorf("findItem() = %+v, %v for an issue outside of the project", item, err)
+	}
+
+	if err = MoveIssueCard(project, "web", 1, "To do", "In progress"); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"project": "PVT_2024", "item": "PVTI_1", "field": "PVTSSF_status", "option": "opt_doing"}
+	if mutations := server.mutations(); len(mutations) != 1 || !reflect.DeepEqual(mutations[0], want) {
+		t.Errorf("the mutations sent are %v, want %v", mutations, want)
+	}
+	if err = MoveIssueCard(project, "web", 9, "To do", "In progress"); err == nil || !strings.Contains(err.Error(), "web#9 is not in the To do column anymore") {
+		t.Errorf("MoveIssueCard() error = %v", err)
+	}
+}
+
+func TestV2BoardYear(t *testing.T) {
+	_, b := newGraphQLTestBackend(t)
+	defer SetBackend(backend)
+	SetBackend(b)
+	defer SetConfig(DefaultConfig())
+	if err := SetConfig(DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { Year = 0 }()
+	Year = 2023
+	project, err := GetProject()
+	if err != nil || project.Name != "Matsuri 2023" {
+		t.Fatalf("GetProject() for 2023 = %v, %v", project, err)
+	}
+	if _, err = getBoard().columns(project); err == nil || !strings.Contains(err.Error(), "has no Status field") {
+		t.Errorf("columns() error = %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package matsuri
+
+import (
+	"github.com/google/go-github/v29/github"
+)
+
+// perPage is the page size requested from list endpoints, the maximum allowed by GitHub.
+const perPage = 100
+
+// pageFunc fetches a single page of a GitHub list endpoint.
+type pageFunc[T any] func(opts github.ListOptions) ([]T, *github.Response, error)
+
+// pager iterates over every item of a GitHub list endpoint, following Response.NextPage until exhausted.
+// Pages are only fetched when needed, so stopping early saves requests.
+type pager[T any] struct {
+	fetch pageFunc[T]
+	opts  github.ListOptions
+	items []T
+	done  bool
+	err   error
+}
+
+func newPager[T any](fetch pageFunc[T]) *pager[T] {
+	return &pager[T]{
+		fetch: fetch,
+		opts:  github.ListOptions{PerPage: perPage},
+	}
+}
+
+// Next returns the next item, or false once all pages have been read or an error occurred.
+func (p *pager[T]) Next() (item T, ok bool) {
+	for len(p.items) == 0 {
+		if p.done {
+			return
+		}
+		items, resp, err := p.fetch(p.opts)
+		if err != nil {
+			p.err = err
+			p.done = true
+			return
+		}
+		p.items = items
+		if resp == nil || resp.NextPage == 0 {
+			p.done = true
+		}
+		if resp != nil {
+			p.opts.Page = resp.NextPage
+		}
+	}
+	item, p.items = p.items[0], p.items[1:]
+	ok = true
+	return
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (p *pager[T]) Err() error {
+	return p.err
+}
+
+// All collects the remaining items, stopping after limit items when limit is positive.
+func (p *pager[T]) All(limit int) (items []T, err error) {
+	for limit <= 0 || len(items) < limit {
+		item, ok := p.Next()
+		if !ok {
+			break
+		}
+		items = append(items, item)
+	}
+	err = p.Err()
+	return
+}
@@ -0,0 +1,102 @@
+package matsuri
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// pages serves items in pages of size, failing with err on the page failAt when it is positive, and counts the requests.
+type pages struct {
+	items    []int
+	size     int
+	failAt   int
+	err      error
+	requests int
+}
+
+func (p *pages) fetch(opts github.ListOptions) ([]int, *github.Response, error) {
+	p.requests++
+	page := opts.Page
+	if page == 0 {
+		page = 1
+	}
+	if page == p.failAt {
+		return nil, nil, p.err
+	}
+	start := (page - 1) * p.size
+	if start >= len(p.items) {
+		return nil, &github.Response{}, nil
+	}
+	end := start + p.size
+	resp := &github.Response{}
+	if end < len(p.items) {
+		resp.NextPage = page + 1
+	} else {
+		end = len(p.items)
+	}
+	return p.items[start:end], resp, nil
+}
+
+func numbers(n int) (items []int) {
+	for i := 1; i <= n; i++ {
+		items = append(items, i)
+	}
+	return
+}
+
+func TestPagerAll(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name         string
+		pages        *pages
+		limit        int
+		want         []int
+		wantErr      error
+		wantRequests int
+	}{
+		{name: "empty", pages: &pages{size: 2}, want: nil, wantRequests: 1},
+		{name: "single page", pages: &pages{items: numbers(2), size: 5}, want: numbers(2), wantRequests: 1},
+		{name: "every page", pages: &pages{items: numbers(7), size: 3}, want: numbers(7), wantRequests: 3},
+		{name: "exact pages", pages: &pages{items: numbers(6), size: 3}, want: numbers(6), wantRequests: 2},
+		{name: "limit stops fetching", pages: &pages{items: numbers(10), size: 3}, limit: 4, want: numbers(4), wantRequests: 2},
+		{name: "limit above the total", pages: &pages{items: numbers(3), size: 2}, limit: 10, want: numbers(3), wantRequests: 2},
+		{
+			name:         "error keeps the items read so far",
+			pages:        &pages{items: numbers(10), size: 3, failAt: 2, err: errBoom},
+			want:         numbers(3),
+			wantErr:      errBoom,
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newPager(tt.pages.fetch).All(tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("All() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("All() = %v, want %v", got, tt.want)
+			}
+			if tt.pages.requests != tt.wantRequests {
+				t.Errorf("All() made %d requests, want %d", tt.pages.requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPagerRequestsFullPages(t *testing.T) {
+	var sizes []int
+	p := newPager(func(opts github.ListOptions) ([]int, *github.Response, error) {
+		sizes = append(sizes, opts.PerPage)
+		return []int{1}, &github.Response{}, nil
+	})
+	if _, err := p.All(0); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sizes, []int{perPage}) {
+		t.Errorf("requested page sizes %v, want [%d]", sizes, perPage)
+	}
+}
@@ -0,0 +1,60 @@
+package matsuri
+
+import (
+	"github.com/google/go-github/v29/github"
+)
+
+// Project is a project board, either a classic project or a Projects (v2) one.
+type Project struct {
+	Name string
+	// Closed projects are only used for the year they were selected for.
+	Closed bool
+
+	classic *github.Project
+	v2      *ProjectV2
+	// v2Items caches the items of a Projects (v2) board, which cannot be listed per column.
+	v2Items []*ProjectV2Item
+}
+
+// Column is a column of a project board. For Projects (v2), it is an option of the Status field.
+type Column struct {
+	Name string
+
+	project *Project
+	classic *github.ProjectColumn
+	option  *ProjectV2Option
+}
+
+// Card is a card of a project board.
+type Card struct {
+	// Repo and Number identify the issue or pull request on the card. Number is 0 for notes and draft issues.
+	Repo   string
+	Number int
+
+	classic *github.ProjectCard
+	item    *ProjectV2Item
+}
+
+// board hides the differences between classic projects and Projects (v2).
+type board interface {
+	// projects lists the projects of the organization, open or closed, oldest first.
+	projects() ([]*Project, error)
+	columns(project *Project) ([]*Column, error)
+	// cards lists the cards of a column, at most limit of them when limit is positive.
+	cards(column *Column, limit int) ([]*Card, error)
+	// findCard gets the card of an issue or pull request in the given column, or nil if it is not there.
+	findCard(column *Column, repo string, number int) (*Card, error)
+	// locateCard gets the card of an issue or pull request wherever it is on the project, along with the name of its column,
+	// or nil if it is not on the project.
+	locateCard(project *Project, repo string, number int) (card *Card, column string, err error)
+	// moveCard moves a card to the top of a column.
+	moveCard(card *Card, column *Column) error
+	addPullRequest(column *Column, pr *github.PullRequest) error
+}
+
+func getBoard() board {
+	if GetConfig().ClassicProjects {
+		return classicBoard{}
+	}
+	return v2Board{}
+}
@@ -0,0 +1,110 @@
+package matsuri
+
+import (
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// classicBoard implements board with the REST API of classic projects.
+type classicBoard struct{}
+
+func listProjectColumns(project *github.Project) *pager[*github.ProjectColumn] {
+	client := GetClient()
+	return newPager(func(opts github.ListOptions) ([]*github.ProjectColumn, *github.Response, error) {
+		return client.Projects.ListProjectColumns(ctx, project.GetID(), &opts)
+	})
+}
+
+func listProjectCards(column *github.ProjectColumn) *pager[*github.ProjectCard] {
+	client := GetClient()
+	return newPager(func(opts github.ListOptions) ([]*github.ProjectCard, *github.Response, error) {
+		return client.Projects.ListProjectCards(ctx, column.GetID(), &github.ProjectCardListOptions{ListOptions: opts})
+	})
+}
+
+func newClassicCard(c *github.ProjectCard) *Card {
+	card := &Card{classic: c}
+	// notes have no content, and content outside of the organization is treated like them
+	repo, number := GetRepoNameFromURL(c.GetContentURL()), GetIssueNumberFromCard(c)
+	if repo != "" && number != 0 {
+		card.Repo, card.Number = repo, number
+	}
+	return card
+}
+
+func (classicBoard) projects() (projects []*Project, err error) {
+	client := GetClient()
+	classicProjects, err := newPager(func(opts github.ListOptions) ([]*github.Project, *github.Response, error) {
+		return client.Organizations.ListProjects(ctx, owner(), &github.ProjectListOptions{State: "all", ListOptions: opts})
+	}).All(0)
+	if err != nil {
+		return
+	}
+	sort.Slice(classicProjects, func(i, j int) bool {
+		return classicProjects[i].GetID() < classicProjects[j].GetID()
+	})
+	for _, p := range classicProjects {
+		projects = append(projects, &Project{Name: p.GetName(), Closed: p.GetState() == "closed", classic: p})
+	}
+	return
+}
+
+func (classicBoard) columns(project *Project) (columns []*Column, err error) {
+	classicColumns, err := listProjectColumns(project.classic).All(0)
+	for _, c := range classicColumns {
+		columns = append(columns, &Column{Name: c.GetName(), project: project, classic: c})
+	}
+	return
+}
+
+func (classicBoard) cards(column *Column, limit int) (cards []*Card, err error) {
+	classicCards, err := listProjectCards(column.classic).All(limit)
+	for _, c := range classicCards {
+		cards = append(cards, newClassicCard(c))
+	}
+	return
+}
+
+func (classicBoard) findCard(column *Column, repo string, number int) (*Card, error) {
+	cards := listProjectCards(column.classic)
+	for c, ok := cards.Next(); ok; c, ok = cards.Next() {
+		if card := newClassicCard(c); card.Repo == repo && card.Number == number {
+			return card, nil
+		}
+	}
+	return nil, cards.Err()
+}
+
+func (b classicBoard) locateCard(project *Project, repo string, number int) (card *Card, column string, err error) {
+	columns, err := b.columns(project)
+	if err != nil {
+		return
+	}
+	for _, c := range columns {
+		if card, err = b.findCard(c, repo, number); err != nil || card != nil {
+			return card, c.Name, err
+		}
+	}
+	return
+}
+
+func (classicBoard) moveCard(card *Card, column *Column) (err error) {
+	opt := &github.ProjectCardMoveOptions{
+		Position: "top",
+		ColumnID: column.classic.GetID(),
+	}
+	client := GetClient()
+	_, err = client.Projects.MoveProjectCard(ctx, card.classic.GetID(), opt)
+	return
+}
+
+func (classicBoard) addPullRequest(column *Column, pr *github.PullRequest) (err error) {
+	cardOpt := &github.ProjectCardOptions{
+		ContentID:   pr.GetID(),
+		ContentType: "PullRequest",
+	}
+	client := GetClient()
+	_, _, err = client.Projects.CreateProjectCard(ctx, column.classic.GetID(), cardOpt)
+	return
+}
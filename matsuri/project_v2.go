@@ -0,0 +1,123 @@
+package matsuri
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// v2Board implements board with the GraphQL API of Projects (v2), where the options
+// of the single-select Status field act as columns.
+type v2Board struct{}
+
+func newV2Card(item *ProjectV2Item) *Card {
+	card := &Card{item: item}
+	if item.ContentType != "DraftIssue" {
+		card.Repo = item.Repository
+		card.Number = item.Number
+	}
+	return card
+}
+
+func (v2Board) projects() (projects []*Project, err error) {
+	client := GetClient()
+	v2Projects, err := client.ProjectsV2.ListProjects(ctx, owner())
+	if err != nil {
+		return
+	}
+	sort.Slice(v2Projects, func(i, j int) bool {
+		return v2Projects[i].Number < v2Projects[j].Number
+	})
+	for _, p := range v2Projects {
+		projects = append(projects, &Project{Name: p.Title, Closed: p.Closed, v2: p})
+	}
+	return
+}
+
+func (v2Board) columns(project *Project) (columns []*Column, err error) {
+	field := project.v2.StatusField
+	if field == nil {
+		err = fmt.Errorf("Error: %s has no Status field", project.Name)
+		return
+	}
+	for _, option := range field.Options {
+		columns = append(columns, &Column{Name: option.Name, project: project, option: option})
+	}
+	return
+}
+
+func (v2Board) items(project *Project) (items []*ProjectV2Item, err error) {
+	if project.v2Items == nil {
+		client := GetClient()
+		project.v2Items, err = client.ProjectsV2.ListItems(ctx, project.v2.ID)
+	}
+	items = project.v2Items
+	return
+}
+
+func (b v2Board) cards(column *Column, limit int) (cards []*Card, err error) {
+	items, err := b.items(column.project)
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		if limit > 0 && len(cards) >= limit {
+			break
+		}
+		if item.Status == column.Name {
+			cards = append(cards, newV2Card(item))
+		}
+	}
+	return
+}
+
+// findItem looks up the project item by the node ID of the issue rather than listing the whole board.
+func (v2Board) findItem(project *Project, repo string, number int) (item *ProjectV2Item, err error) {
+	client := GetClient()
+	issue, _, err := client.Issues.Get(ctx, owner(), repo, number)
+	if err != nil {
+		return
+	}
+	return client.ProjectsV2.GetItemForContent(ctx, project.v2.ID, issue.GetNodeID())
+}
+
+func (b v2Board) findCard(column *Column, repo string, number int) (card *Card, err error) {
+	item, err := b.findItem(column.project, repo, number)
+	if err != nil || item == nil || item.Status != column.Name {
+		return
+	}
+	card = newV2Card(item)
+	return
+}
+
+func (b v2Board) locateCard(project *Project, repo string, number int) (card *Card, column string, err error) {
+	item, err := b.findItem(project, repo, number)
+	if err != nil || item == nil {
+		return
+	}
+	return newV2Card(item), item.Status, nil
+}
+
+func (v2Board) setStatus(item *ProjectV2Item, column *Column) (err error) {
+	client := GetClient()
+	project := column.project.v2
+	err = client.ProjectsV2.SetItemStatus(ctx, project.ID, item.ID, project.StatusField.ID, column.option.ID)
+	if err == nil {
+		item.Status = column.Name
+	}
+	return
+}
+
+func (b v2Board) moveCard(card *Card, column *Column) error {
+	return b.setStatus(card.item, column)
+}
+
+func (b v2Board) addPullRequest(column *Column, pr *github.PullRequest) (err error) {
+	client := GetClient()
+	item, err := client.ProjectsV2.AddItem(ctx, column.project.v2.ID, pr.GetNodeID())
+	if err != nil {
+		return
+	}
+	return b.setStatus(item, column)
+}
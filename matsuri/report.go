@@ -0,0 +1,233 @@
+package matsuri
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"gopkg.in/yaml.v3"
+)
+
+// ReportSchemaVersion is the version of the structure of the json and yaml reports.
+// It is incremented whenever a field is renamed or removed, but not when one is added.
+const ReportSchemaVersion = 1
+
+// Output formats of todo and kanban.
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the supported output formats.
+var Formats = []string{FormatText, FormatJSON, FormatYAML, FormatCSV, FormatMarkdown}
+
+// ValidateFormat checks that format is one of Formats.
+func ValidateFormat(format string) error {
+	for _, f := range Formats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, use one of: %s", format, strings.Join(Formats, ", "))
+}
+
+// ReportIssue is an issue as shown in reports.
+type ReportIssue struct {
+	Number     int    `json:"number" yaml:"number"`
+	Repository string `json:"repository" yaml:"repository"`
+	Title      string `json:"title" yaml:"title"`
+	URL        string `json:"url" yaml:"url"`
+	// Column is the project column holding the issue, empty when it is not known.
+	Column    string    `json:"column" yaml:"column"`
+	Labels    []string  `json:"labels" yaml:"labels"`
+	Assignees []string  `json:"assignees" yaml:"assignees"`
+	Milestone string    `json:"milestone" yaml:"milestone"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+	// Body is left out of the reports, but shown in the interactive views.
+	Body string `json:"-" yaml:"-"`
+	// PullRequest is left out of the reports too, the interactive views cannot start working on pull requests.
+	PullRequest bool `json:"-" yaml:"-"`
+}
+
+// NewReportIssue converts an issue of the given repository and column.
+func NewReportIssue(issue *github.Issue, repo, column string) *ReportIssue {
+	i := &ReportIssue{
+		Number:      issue.GetNumber(),
+		Repository:  repo,
+		Title:       issue.GetTitle(),
+		URL:         issue.GetHTMLURL(),
+		Column:      column,
+		Labels:      []string{},
+		Assignees:   []string{},
+		Milestone:   issue.GetMilestone().GetTitle(),
+		CreatedAt:   issue.GetCreatedAt(),
+		UpdatedAt:   issue.GetUpdatedAt(),
+		Body:        issue.GetBody(),
+		PullRequest: issue.IsPullRequest(),
+	}
+	for _, label := range issue.Labels {
+		i.Labels = append(i.Labels, label.GetName())
+	}
+	for _, assignee := range issue.Assignees {
+		i.Assignees = append(i.Assignees, assignee.GetLogin())
+	}
+	return i
+}
+
+// IssuesReport is the report of `git matsuri todo`.
+type IssuesReport struct {
+	SchemaVersion int            `json:"schema_version" yaml:"schema_version"`
+	Issues        []*ReportIssue `json:"issues" yaml:"issues"`
+	// GroupBy groups the issues under headings in the text and markdown formats.
+	GroupBy string `json:"-" yaml:"-"`
+}
+
+// NewIssuesReport converts issues, found in the given column if they come from a project.
+func NewIssuesReport(issues []*github.Issue, column string) *IssuesReport {
+	r := &IssuesReport{SchemaVersion: ReportSchemaVersion, Issues: []*ReportIssue{}}
+	for _, issue := range issues {
+		// sanity check
+		if issue.IsPullRequest() {
+			continue
+		}
+		repoName := GetRepoNameFromURL(issue.GetRepositoryURL())
+		if repoName == "" {
+			continue
+		}
+		r.Issues = append(r.Issues, NewReportIssue(issue, repoName, column))
+	}
+	return r
+}
+
+// KanbanColumn is a column of a KanbanReport.
+type KanbanColumn struct {
+	Name   string         `json:"name" yaml:"name"`
+	Issues []*ReportIssue `json:"issues" yaml:"issues"`
+}
+
+// KanbanReport is the report of `git matsuri kanban`.
+type KanbanReport struct {
+	SchemaVersion int             `json:"schema_version" yaml:"schema_version"`
+	Project       string          `json:"project" yaml:"project"`
+	Columns       []*KanbanColumn `json:"columns" yaml:"columns"`
+}
+
+// csvHeader is the first row of csv reports.
+var csvHeader = []string{"number", "repository", "title", "url", "column", "labels", "assignees", "milestone", "created_at", "updated_at"}
+
+func (i *ReportIssue) csvRecord() []string {
+	return []string{
+		strconv.Itoa(i.Number), i.Repository, i.Title, i.URL, i.Column,
+		strings.Join(i.Labels, ";"), strings.Join(i.Assignees, ";"), i.Milestone,
+		i.CreatedAt.Format(time.RFC3339), i.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// markdownEscaper keeps titles from breaking markdown tables.
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "\r", "")
+
+func writeMarkdownTable(w io.Writer, issues []*ReportIssue) {
+	fmt.Fprintln(w, "| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |")
+	fmt.Fprintln(w, "|---|---|---|---|---|---|---|---|")
+	for _, i := range issues {
+		fmt.Fprintf(w, "| [%d](%s) | %s | %s | %s | %s | %s | %s | %s |\n",
+			i.Number, i.URL, i.Repository, markdownEscaper.Replace(i.Title),
+			markdownEscaper.Replace(strings.Join(i.Labels, ", ")), strings.Join(i.Assignees, ", "),
+			markdownEscaper.Replace(i.Milestone), i.CreatedAt.Format("2006-01-02"), i.UpdatedAt.Format("2006-01-02"))
+	}
+}
+
+func writeStructured(w io.Writer, format string, v interface{}) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Write writes the report in the given format.
+func (r *IssuesReport) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON, FormatYAML:
+		return writeStructured(w, format, r)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write(csvHeader)
+		for _, i := range r.Issues {
+			_ = writer.Write(i.csvRecord())
+		}
+		writer.Flush()
+		return writer.Error()
+	case FormatMarkdown:
+		for n, group := range groupIssues(r.Issues, r.GroupBy) {
+			if group.name != "" {
+				if n != 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "## %s\n\n", markdownEscaper.Replace(group.name))
+			}
+			writeMarkdownTable(w, group.issues)
+		}
+		return nil
+	}
+	for n, group := range groupIssues(r.Issues, r.GroupBy) {
+		if group.name != "" {
+			if n != 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, group.name)
+		}
+		for _, i := range group.issues {
+			fmt.Fprintf(w, "%d [%s]: %s\n", i.Number, i.Repository, i.Title)
+		}
+	}
+	return nil
+}
+
+// Write writes the report in the given format.
+func (r *KanbanReport) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatJSON, FormatYAML:
+		return writeStructured(w, format, r)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write(csvHeader)
+		for _, column := range r.Columns {
+			for _, i := range column.Issues {
+				_ = writer.Write(i.csvRecord())
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case FormatMarkdown:
+		fmt.Fprintf(w, "# %s\n", r.Project)
+		for _, column := range r.Columns {
+			fmt.Fprintf(w, "\n## %s\n\n", column.Name)
+			writeMarkdownTable(w, column.Issues)
+		}
+		return nil
+	}
+	for _, column := range r.Columns {
+		fmt.Fprintln(w, column.Name)
+		for _, i := range column.Issues {
+			fmt.Fprintf(w, "%d [%s]: %s\n", i.Number, i.Repository, i.Title)
+		}
+		fmt.Fprintln(w)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package matsuri
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// kanbanTemplate renders a KanbanReport as a page without external assets, so that it can be shared as a single file.
+var kanbanTemplate = template.Must(template.New("kanban").Funcs(template.FuncMap{
+	"age": age,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Report.Project}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; padding: 1.5em; background: #f6f8fa; color: #1f2328; }
+h1 { font-size: 1.5em; margin: 0 0 .2em; }
+.generated { color: #656d76; margin: 0 0 1.5em; font-size: .9em; }
+.board { display: flex; gap: 1em; align-items: flex-start; overflow-x: auto; }
+.column { flex: 0 0 18em; background: #eaeef2; border-radius: 6px; padding: .6em; }
+.column h2 { font-size: 1em; margin: .2em .2em .6em; }
+.count { color: #656d76; font-weight: normal; }
+.card { background: #fff; border: 1px solid #d0d7de; border-radius: 6px; padding: .6em; margin-bottom: .6em; }
+.card a { color: inherit; text-decoration: none; font-weight: 600; }
+.card a:hover { text-decoration: underline; }
+.meta { color: #656d76; font-size: .85em; margin-top: .3em; }
+.label { display: inline-block; background: #ddf4ff; color: #0969da; border-radius: 2em; padding: 0 .6em; margin: .3em .2em 0 0; font-size: .8em; }
+</style>
+</head>
+<body>
+<h1>{{.Report.Project}}</h1>
+<p class="generated">Generated on {{.Now.Format "2006-01-02 15:04"}}</p>
+<div class="board">
+{{- range .Report.Columns}}
+<section class="column">
+<h2>{{.Name}} <span class="count">{{len .Issues}}</span></h2>
+{{- range .Issues}}
+<article class="card">
+<a href="{{.URL}}">{{.Title}}</a>
+<div class="meta">{{.Repository}}#{{.Number}} · opened {{age .CreatedAt $.Now}} ago{{if .Assignees}} · {{range $i, $a := .Assignees}}{{if $i}}, {{end}}@{{$a}}{{end}}{{end}}</div>
+{{- if .Labels}}
+<div>{{range .Labels}}<span class="label">{{.}}</span>{{end}}</div>
+{{- end}}
+</article>
+{{- end}}
+</section>
+{{- end}}
+</div>
+</body>
+</html>
+`))
+
+// age describes how long ago t was, in the largest whole unit.
+func age(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+// WriteHTML writes the report as a self-contained HTML page, ages being computed as of now.
+func (r *KanbanReport) WriteHTML(w io.Writer, now time.Time) error {
+	return kanbanTemplate.Execute(w, struct {
+		Report *KanbanReport
+		Now    time.Time
+	}{r, now})
+}
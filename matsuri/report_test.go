@@ -0,0 +1,260 @@
+package matsuri
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+// testIssue makes an issue of the given repository, as listed by the search API.
+func testIssue(number int, repo, title string, labels, assignees []string) *github.Issue {
+	issue := &github.Issue{
+		Number:        github.Int(number),
+		Title:         github.String(title),
+		RepositoryURL: github.String("https://api.github.com/repos/MatsuriJapon/" + repo),
+	}
+	for _, label := range labels {
+		issue.Labels = append(issue.Labels, github.Label{Name: github.String(label)})
+	}
+	for _, assignee := range assignees {
+		issue.Assignees = append(issue.Assignees, &github.User{Login: github.String(assignee)})
+	}
+	return issue
+}
+
+func testReportIssues() []*ReportIssue {
+	created := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 3, 2, 18, 30, 0, 0, time.UTC)
+	return []*ReportIssue{
+		{
+			Number: 1, Repository: "web", Title: "Fix | the header", URL: "https://github.com/MatsuriJapon/web/issues/1",
+			Column: "To do", Labels: []string{"bug", "web"}, Assignees: []string{"alice"}, Milestone: "2024",
+			CreatedAt: created, UpdatedAt: updated,
+		},
+		{
+			Number: 2, Repository: "api", Title: "Add, a \"footer\"", URL: "https://github.com/MatsuriJapon/api/issues/2",
+			Column: "To do", Labels: []string{}, Assignees: []string{}, CreatedAt: created, UpdatedAt: updated,
+		},
+	}
+}
+
+func TestIssuesReportWrite(t *testing.T) {
+	tests := []struct {
+		format  string
+		groupBy string
+		want    string
+	}{
+		{
+			format: FormatText,
+			want:   "1 [web]: Fix | the header\n2 [api]: Add, a \"footer\"\n",
+		},
+		{
+			format:  FormatText,
+			groupBy: GroupByRepo,
+			want:    "web\n1 [web]: Fix | the header\n\napi\n2 [api]: Add, a \"footer\"\n",
+		},
+		{
+			format: FormatCSV,
+			want: "number,repository,title,url,column,labels,assignees,milestone,created_at,updated_at\n" +
+				"1,web,Fix | the header,https://github.com/MatsuriJapon/web/issues/1,To do,bug;web,alice,2024,2024-03-01T09:00:00Z,2024-03-02T18:30:00Z\n" +
+				"2,api,\"Add, a \"\"footer\"\"\",https://github.com/MatsuriJapon/api/issues/2,To do,,,,2024-03-01T09:00:00Z,2024-03-02T18:30:00Z\n",
+		},
+		{
+			format: FormatMarkdown,
+			want: "| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [1](https://github.com/MatsuriJapon/web/issues/1) | web | Fix \\| the header | bug, web | alice | 2024 | 2024-03-01 | 2024-03-02 |\n" +
+				"| [2](https://github.com/MatsuriJapon/api/issues/2) | api | Add, a \"footer\" |  |  |  | 2024-03-01 | 2024-03-02 |\n",
+		},
+		{
+			format:  FormatMarkdown,
+			groupBy: GroupByLabel,
+			want: "## bug\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [1](https://github.com/MatsuriJapon/web/issues/1) | web | Fix \\| the header | bug, web | alice | 2024 | 2024-03-01 | 2024-03-02 |\n" +
+				"\n## web\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [1](https://github.com/MatsuriJapon/web/issues/1) | web | Fix \\| the header | bug, web | alice | 2024 | 2024-03-01 | 2024-03-02 |\n" +
+				"\n## (none)\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [2](https://github.com/MatsuriJapon/api/issues/2) | api | Add, a \"footer\" |  |  |  | 2024-03-01 | 2024-03-02 |\n",
+		},
+		{
+			format: FormatJSON,
+			want: `{
+  "schema_version": 1,
+  "issues": [
+    {
+      "number": 1,
+      "repository": "web",
+      "title": "Fix | the header",
+      "url": "https://github.com/MatsuriJapon/web/issues/1",
+      "column": "To do",
+      "labels": [
+        "bug",
+        "web"
+      ],
+      "assignees": [
+        "alice"
+      ],
+      "milestone": "2024",
+      "created_at": "2024-03-01T09:00:00Z",
+      "updated_at": "2024-03-02T18:30:00Z"
+    },
+    {
+      "number": 2,
+      "repository": "api",
+      "title": "Add, a \"footer\"",
+      "url": "https://github.com/MatsuriJapon/api/issues/2",
+      "column": "To do",
+      "labels": [],
+      "assignees": [],
+      "milestone": "",
+      "created_at": "2024-03-01T09:00:00Z",
+      "updated_at": "2024-03-02T18:30:00Z"
+    }
+  ]
+}
+`,
+		},
+		{
+			format: FormatYAML,
+			want: `schema_version: 1
+issues:
+  - number: 1
+    repository: web
+    title: Fix | the header
+    url: https://github.com/MatsuriJapon/web/issues/1
+    column: To do
+    labels:
+      - bug
+      - web
+    assignees:
+      - alice
+    milestone: "2024"
+    created_at: 2024-03-01T09:00:00Z
+    updated_at: 2024-03-02T18:30:00Z
+  - number: 2
+    repository: api
+    title: Add, a "footer"
+    url: https://github.com/MatsuriJapon/api/issues/2
+    column: To do
+    labels: []
+    assignees: []
+    milestone: ""
+    created_at: 2024-03-01T09:00:00Z
+    updated_at: 2024-03-02T18:30:00Z
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.groupBy, func(t *testing.T) {
+			report := &IssuesReport{SchemaVersion: ReportSchemaVersion, Issues: testReportIssues(), GroupBy: tt.groupBy}
+			var out bytes.Buffer
+			if err := report.Write(&out, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanbanReportWrite(t *testing.T) {
+	issues := testReportIssues()
+	report := &KanbanReport{
+		SchemaVersion: ReportSchemaVersion,
+		Project:       "Matsuri 2024",
+		Columns: []*KanbanColumn{
+			{Name: "To do", Issues: issues[:1]},
+			{Name: "Done", Issues: []*ReportIssue{}},
+			{Name: "In progress", Issues: issues[1:]},
+		},
+	}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: FormatText,
+			want:   "To do\n1 [web]: Fix | the header\n\nDone\n\nIn progress\n2 [api]: Add, a \"footer\"\n\n",
+		},
+		{
+			format: FormatMarkdown,
+			want: "# Matsuri 2024\n" +
+				"\n## To do\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [1](https://github.com/MatsuriJapon/web/issues/1) | web | Fix \\| the header | bug, web | alice | 2024 | 2024-03-01 | 2024-03-02 |\n" +
+				"\n## Done\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"\n## In progress\n\n" +
+				"| # | Repository | Title | Labels | Assignees | Milestone | Created | Updated |\n" +
+				"|---|---|---|---|---|---|---|---|\n" +
+				"| [2](https://github.com/MatsuriJapon/api/issues/2) | api | Add, a \"footer\" |  |  |  | 2024-03-01 | 2024-03-02 |\n",
+		},
+		{
+			format: FormatCSV,
+			want: "number,repository,title,url,column,labels,assignees,milestone,created_at,updated_at\n" +
+				"1,web,Fix | the header,https://github.com/MatsuriJapon/web/issues/1,To do,bug;web,alice,2024,2024-03-01T09:00:00Z,2024-03-02T18:30:00Z\n" +
+				"2,api,\"Add, a \"\"footer\"\"\",https://github.com/MatsuriJapon/api/issues/2,To do,,,,2024-03-01T09:00:00Z,2024-03-02T18:30:00Z\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := report.Write(&out, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("Write() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		var out bytes.Buffer
+		if err := report.Write(&out, format); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "Matsuri 2024") || !strings.Contains(out.String(), "In progress") {
+			t.Errorf("the %s report lacks the project or a column:\n%s", format, out.String())
+		}
+	}
+}
+
+func TestNewIssuesReport(t *testing.T) {
+	pr := testIssue(3, "web", "A pull request", nil, nil)
+	pr.PullRequestLinks = &github.PullRequestLinks{URL: github.String("https://api.github.com/repos/MatsuriJapon/web/pulls/3")}
+	other := testIssue(4, "web", "Elsewhere", nil, nil)
+	other.RepositoryURL = github.String("https://api.github.com/repos/someone/web")
+	issues := []*github.Issue{testIssue(1, "web", "Fix", []string{"bug"}, []string{"alice"}), pr, other}
+	report := NewIssuesReport(issues, "To do")
+	if len(report.Issues) != 1 {
+		t.Fatalf("NewIssuesReport() kept %d issues, want the pull request and the issue of another owner left out", len(report.Issues))
+	}
+	got := report.Issues[0]
+	if got.Number != 1 || got.Repository != "web" || got.Column != "To do" || got.Labels[0] != "bug" || got.Assignees[0] != "alice" {
+		t.Errorf("NewIssuesReport() = %+v", got)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range Formats {
+		if err := ValidateFormat(format); err != nil {
+			t.Errorf("ValidateFormat(%q) error = %v", format, err)
+		}
+	}
+	for _, format := range []string{"", "JSON", "xml"} {
+		if err := ValidateFormat(format); err == nil {
+			t.Errorf("ValidateFormat(%q) accepted the format", format)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package matsuri
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+var (
+	// MaxRetries is the number of times a request is retried after a rate limit or a server error.
+	MaxRetries = 3
+	// MaxRetryWait is the longest git-matsuri waits before retrying a request.
+	// Rate limits resetting later than that are reported instead.
+	MaxRetryWait = time.Minute
+)
+
+// retryTransport retries requests rejected by a rate limit once it resets, honoring Retry-After and X-RateLimit-Reset,
+// and retries idempotent requests failing with a server error with an exponential backoff.
+type retryTransport struct {
+	base  http.RoundTripper
+	sleep func(req *http.Request, d time.Duration) error
+}
+
+func newRetryTransport(base http.RoundTripper) http.RoundTripper {
+	return &retryTransport{base: base, sleep: sleepContext}
+}
+
+func sleepContext(req *http.Request, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// backoff gets the exponential delay before the given retry: 1s, 2s, 4s...
+func backoff(attempt int) time.Duration {
+	return time.Second << uint(attempt)
+}
+
+// isSecondaryRateLimit peeks at the body of a 403 response, which is then restored, to tell secondary rate limits apart.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+// retryDelay decides whether a response should be retried, and after how long.
+func retryDelay(req *http.Request, resp *http.Response, attempt int, now time.Time) (wait time.Duration, retry bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return
+			}
+			// a second of margin for clock differences
+			return time.Unix(reset, 0).Sub(now) + time.Second, true
+		}
+		if isSecondaryRateLimit(resp) {
+			// GitHub asks to wait at least a minute when no Retry-After is given
+			return time.Minute, true
+		}
+	case resp.StatusCode >= http.StatusInternalServerError && isIdempotent(req):
+		return backoff(attempt), true
+	}
+	return
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			// RoundTrip must not modify the caller's request
+			req = req.Clone(req.Context())
+			if req.Body, err = req.GetBody(); err != nil {
+				return
+			}
+		}
+		resp, err = t.base.RoundTrip(req)
+		var wait time.Duration
+		retry := false
+		if err != nil {
+			wait, retry = backoff(attempt), isIdempotent(req)
+		} else {
+			wait, retry = retryDelay(req, resp, attempt, time.Now())
+		}
+		// a body that cannot be sent again leaves the response to the caller, unread
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if !retry || !replayable || attempt >= MaxRetries || wait > MaxRetryWait {
+			return
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err = t.sleep(req, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DescribeError turns rate limit errors from the GitHub API into a message saying when to try again.
+// Other errors are returned as is.
+func DescribeError(err error) error {
+	var rateLimitErr *github.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		reset := rateLimitErr.Rate.Reset.Time
+		return fmt.Errorf("the GitHub API rate limit of %d requests per hour is exhausted, it resets at %s (in %s)",
+			rateLimitErr.Rate.Limit, reset.Local().Format("15:04:05"), time.Until(reset).Round(time.Second))
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		if abuseErr.RetryAfter != nil {
+			return fmt.Errorf("the GitHub API secondary rate limit was hit, try again in %s or lower --concurrency", abuseErr.RetryAfter.Round(time.Second))
+		}
+		return errors.New("the GitHub API secondary rate limit was hit, try again in a few minutes or lower --concurrency")
+	}
+	var responseErr *github.ErrorResponse
+	if errors.As(err, &responseErr) && strings.Contains(strings.ToLower(responseErr.Message), "secondary rate limit") {
+		return errors.New("the GitHub API secondary rate limit was hit, try again in a few minutes or lower --concurrency")
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package matsuri
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	response := func(status int, header map[string]string, body string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}
+		for key, value := range header {
+			resp.Header.Set(key, value)
+		}
+		return resp
+	}
+	get, _ := http.NewRequest(http.MethodGet, "https://api.github.com/", nil)
+	post, _ := http.NewRequest(http.MethodPost, "https://api.github.com/", nil)
+	tests := []struct {
+		name      string
+		req       *http.Request
+		resp      *http.Response
+		attempt   int
+		wantWait  time.Duration
+		wantRetry bool
+	}{
+		{name: "success", req: get, resp: response(http.StatusOK, nil, "")},
+		{name: "not found", req: get, resp: response(http.StatusNotFound, nil, "")},
+		{
+			name:      "retry after",
+			req:       post,
+			resp:      response(http.StatusForbidden, map[string]string{"Retry-After": "30"}, ""),
+			wantWait:  30 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name:      "too many requests",
+			req:       get,
+			resp:      response(http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}, ""),
+			wantWait:  5 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name: "primary rate limit waits for the reset",
+			req:  get,
+			resp: response(http.StatusForbidden, map[string]string{
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     strconv.FormatInt(now.Add(20*time.Second).Unix(), 10),
+			}, ""),
+			wantWait:  21 * time.Second,
+			wantRetry: true,
+		},
+		{
+			name: "invalid reset",
+			req:  get,
+			resp: response(http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "soon"}, ""),
+		},
+		{
+			name:      "secondary rate limit without retry after",
+			req:       get,
+			resp:      response(http.StatusForbidden, nil, `{"message":"You have exceeded a secondary rate limit"}`),
+			wantWait:  time.Minute,
+			wantRetry: true,
+		},
+		{name: "forbidden", req: get, resp: response(http.StatusForbidden, nil, `{"message":"Resource not accessible"}`)},
+		{name: "server error", req: get, resp: response(http.StatusBadGateway, nil, ""), attempt: 2, wantWait: 4 * time.Second, wantRetry: true},
+		{name: "server error on a write", req: post, resp: response(http.StatusBadGateway, nil, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, retry := retryDelay(tt.req, tt.resp, tt.attempt, now)
+			if wait != tt.wantWait || retry != tt.wantRetry {
+				t.Errorf("retryDelay() = %s, %v, want %s, %v", wait, retry, tt.wantWait, tt.wantRetry)
+			}
+		})
+	}
+}
+
+// newTestRetryTransport retries without sleeping, recording the waits.
+func newTestRetryTransport(waits *[]time.Duration) *retryTransport {
+	return &retryTransport{
+		base: http.DefaultTransport,
+		sleep: func(_ *http.Request, d time.Duration) error {
+			*waits = append(*waits, d)
+			return nil
+		},
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name string
+		// statuses are the answers of the server, in order, the last one being repeated
+		statuses     []int
+		method       string
+		body         io.Reader
+		wantStatus   int
+		wantRequests int
+		wantBody     string
+	}{
+		{name: "success", statuses: []int{200}, method: http.MethodGet, wantStatus: 200, wantRequests: 1, wantBody: "answer 1"},
+		{name: "server error then success", statuses: []int{502, 200}, method: http.MethodGet, wantStatus: 200, wantRequests: 2, wantBody: "answer 2"},
+		{name: "gives up after MaxRetries", statuses: []int{503}, method: http.MethodGet, wantStatus: 503, wantRequests: MaxRetries + 1, wantBody: fmt.Sprintf("answer %d", MaxRetries+1)},
+		{name: "rate limited write with a replayable body", statuses: []int{429, 201}, method: http.MethodPost, body: strings.NewReader("{}"), wantStatus: 201, wantRequests: 2, wantBody: "answer 2"},
+		{
+			name:         "body that cannot be replayed",
+			statuses:     []int{429, 201},
+			method:       http.MethodPost,
+			body:         io.MultiReader(strings.NewReader("{}")),
+			wantStatus:   429,
+			wantRequests: 1,
+			wantBody:     "answer 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests := int(atomic.AddInt32(&count, 1))
+				if r.Method == http.MethodPost {
+					if body, _ := io.ReadAll(r.Body); string(body) != "{}" {
+						t.Errorf("request %d has body %q", requests, body)
+					}
+				}
+				status := tt.statuses[len(tt.statuses)-1]
+				if requests <= len(tt.statuses) {
+					status = tt.statuses[requests-1]
+				}
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(status)
+				fmt.Fprintf(w, "answer %d", requests)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			req, err := http.NewRequest(tt.method, server.URL, tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := newTestRetryTransport(&waits).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("the response body cannot be read: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody {
+				t.Errorf("RoundTrip() = %d %q, want %d %q", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+			if requests := int(atomic.LoadInt32(&count)); requests != tt.wantRequests || len(waits) != tt.wantRequests-1 {
+				t.Errorf("RoundTrip() made %d requests and %d waits, want %d requests", requests, len(waits), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDescribeError(t *testing.T) {
+	rateLimitErr := &github.RateLimitError{Rate: github.Rate{Limit: 5000, Reset: github.Timestamp{Time: time.Now().Add(time.Hour)}}}
+	plain := errors.New("plain")
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "rate limit", err: rateLimitErr, want: "rate limit of 5000 requests per hour is exhausted"},
+		{name: "wrapped rate limit", err: fmt.Errorf("web#1: %w", rateLimitErr), want: "rate limit of 5000 requests per hour is exhausted"},
+		{name: "rate limit among fetch errors", err: FetchErrors{plain, fmt.Errorf("web#2: %w", rateLimitErr)}, want: "rate limit of 5000 requests per hour is exhausted"},
+		{name: "abuse", err: &github.AbuseRateLimitError{}, want: "secondary rate limit was hit"},
+		{name: "other", err: plain, want: "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeError(tt.err).Error(); !strings.Contains(got, tt.want) {
+				t.Errorf("DescribeError() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package matsuri
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Permission is a kind of access to GitHub a command needs from the token.
+type Permission string
+
+const (
+	// PermissionRepo is access to the repositories of the organization.
+	PermissionRepo Permission = "repo"
+	// PermissionEmail is access to the email addresses of the user.
+	PermissionEmail Permission = "email"
+	// PermissionProjectRead is read access to the project boards.
+	PermissionProjectRead Permission = "project:read"
+	// PermissionProjectWrite is write access to the project boards.
+	PermissionProjectWrite Permission = "project:write"
+)
+
+// TokenCheckTTL is how long the result of a token check is reused before checking again.
+var TokenCheckTTL = 24 * time.Hour
+
+// impliedScopes lists the OAuth scopes granted along with a broader one.
+var impliedScopes = map[string][]string{
+	"repo":      {"repo:status", "repo_deployment", "public_repo", "repo:invite", "security_events"},
+	"admin:org": {"write:org", "read:org"},
+	"write:org": {"read:org"},
+	"project":   {"read:project"},
+	"user":      {"read:user", "user:email", "user:follow"},
+}
+
+// scopes gets the OAuth scopes required by a permission, which depend on the kind of project boards.
+func (p Permission) scopes() []string {
+	switch p {
+	case PermissionRepo:
+		return []string{"repo"}
+	case PermissionEmail:
+		return []string{"user:email"}
+	case PermissionProjectRead:
+		if GetConfig().ClassicProjects {
+			return []string{"repo", "read:org"}
+		}
+		return []string{"read:project"}
+	case PermissionProjectWrite:
+		if GetConfig().ClassicProjects {
+			return []string{"repo", "read:org"}
+		}
+		return []string{"project"}
+	}
+	return nil
+}
+
+// TokenInfo is what GitHub reports about a token.
+type TokenInfo struct {
+	Login string `json:"login"`
+	// Scopes are the OAuth scopes of a classic token, and nil for fine-grained tokens.
+	Scopes []string `json:"scopes"`
+	// Probes records the permissions a fine-grained token was found to allow.
+	Probes    map[Permission]bool `json:"probes"`
+	CheckedAt time.Time           `json:"checked_at"`
+}
+
+// FineGrained reports whether the token has no OAuth scopes, such as fine-grained personal access tokens.
+func (i *TokenInfo) FineGrained() bool {
+	return i.Scopes == nil
+}
+
+func (i *TokenInfo) hasScope(scope string) bool {
+	for _, granted := range i.Scopes {
+		if granted == scope {
+			return true
+		}
+		for _, implied := range impliedScopes[granted] {
+			if implied == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenInfoPath gets the file caching what is known about the token, named after a hash of it.
+func tokenInfoPath(t string) (path string, err error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(apiHost() + "\x00" + t))
+	path = filepath.Join(dir, "git-matsuri", "tokens", hex.EncodeToString(sum[:]))
+	return
+}
+
+func loadTokenInfo(t string) *TokenInfo {
+	path, err := tokenInfoPath(t)
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(path) // #nosec
+	if err != nil {
+		return nil
+	}
+	info := &TokenInfo{}
+	if json.Unmarshal(content, info) != nil || time.Since(info.CheckedAt) > TokenCheckTTL {
+		return nil
+	}
+	if info.Probes == nil {
+		info.Probes = map[Permission]bool{}
+	}
+	return info
+}
+
+func saveTokenInfo(t string, info *TokenInfo) (err error) {
+	path, err := tokenInfoPath(t)
+	if err != nil {
+		return
+	}
+	content, err := json.Marshal(info)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	return os.WriteFile(path, content, 0o600)
+}
+
+// GetTokenInfo gets the user and scopes of the current token, from the cache if it was checked recently.
+func GetTokenInfo() (info *TokenInfo, err error) {
+	t, _, err := FindToken()
+	if err != nil {
+		return
+	}
+	if info = loadTokenInfo(t); info != nil {
+		return
+	}
+	client := GetClient()
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return
+	}
+	info = &TokenInfo{
+		Login:     user.GetLogin(),
+		Probes:    map[Permission]bool{},
+		CheckedAt: time.Now(),
+	}
+	if values, found := resp.Header["X-Oauth-Scopes"]; found {
+		info.Scopes = []string{}
+		for _, scope := range strings.Split(strings.Join(values, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				info.Scopes = append(info.Scopes, scope)
+			}
+		}
+	}
+	// failing to cache only means checking again next time
+	_ = saveTokenInfo(t, info)
+	return
+}
+
+// probe checks a permission of a fine-grained token by calling an endpoint that needs it.
+// Write access to projects cannot be checked without modifying them, so it is assumed.
+func probe(p Permission) bool {
+	client := GetClient()
+	switch p {
+	case PermissionRepo:
+		repoName, err := GetRepoName()
+		if err != nil {
+			// outside of a repository there is nothing to probe yet
+			return true
+		}
+		_, _, err = client.Repositories.Get(ctx, owner(), repoName)
+		return err == nil
+	case PermissionEmail:
+		_, _, err := client.Users.ListEmails(ctx, nil)
+		return err == nil
+	case PermissionProjectRead:
+		_, err := getBoard().projects()
+		return err == nil
+	}
+	return true
+}
+
+// ValidateToken checks that the current token grants the given permissions, naming the command in the error.
+// Classic tokens are checked against their OAuth scopes, fine-grained ones by probing endpoints.
+func ValidateToken(command string, permissions []Permission) (err error) {
+	if len(permissions) == 0 {
+		return
+	}
+	info, err := GetTokenInfo()
+	if err != nil {
+		return DescribeError(err)
+	}
+
+	if info.FineGrained() {
+		var denied []string
+		probed := false
+		for _, p := range permissions {
+			allowed := info.Probes[p]
+			if !allowed {
+				// only successes are cached, so that a token whose permissions were fixed is not refused until the cache expires
+				allowed = probe(p)
+				info.Probes[p] = allowed
+				probed = probed || allowed
+			}
+			if !allowed {
+				denied = append(denied, string(p))
+			}
+		}
+		if probed {
+			if t, _, findErr := FindToken(); findErr == nil {
+				_ = saveTokenInfo(t, info)
+			}
+		}
+		if len(denied) != 0 {
+			return fmt.Errorf("the token of %s does not allow `%s` to access: %s\nEdit its permissions at https://github.com/settings/tokens",
+				info.Login, command, strings.Join(denied, ", "))
+		}
+		return
+	}
+
+	missing := map[string]bool{}
+	for _, p := range permissions {
+		for _, scope := range p.scopes() {
+			if !info.hasScope(scope) {
+				missing[scope] = true
+			}
+		}
+	}
+	if len(missing) != 0 {
+		scopes := make([]string, 0, len(missing))
+		for scope := range missing {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		return fmt.Errorf("the token of %s is missing the following scopes for `%s`: %s\nAdd them at https://github.com/settings/tokens",
+			info.Login, command, strings.Join(scopes, ", "))
+	}
+	return
+}
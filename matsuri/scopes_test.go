@@ -0,0 +1,58 @@
+package matsuri
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		granted []string
+		scope   string
+		want    bool
+	}{
+		{granted: []string{"repo"}, scope: "repo", want: true},
+		{granted: []string{"repo"}, scope: "public_repo", want: true},
+		{granted: []string{"public_repo"}, scope: "repo", want: false},
+		{granted: []string{"admin:org"}, scope: "read:org", want: true},
+		{granted: []string{"write:org"}, scope: "read:org", want: true},
+		{granted: []string{"read:org"}, scope: "write:org", want: false},
+		{granted: []string{"project"}, scope: "read:project", want: true},
+		{granted: []string{"read:project"}, scope: "project", want: false},
+		{granted: []string{"user"}, scope: "user:email", want: true},
+		{granted: []string{"repo", "user:email"}, scope: "user:email", want: true},
+		{granted: []string{}, scope: "repo", want: false},
+	}
+	for _, tt := range tests {
+		info := &TokenInfo{Scopes: tt.granted}
+		if got := info.hasScope(tt.scope); got != tt.want {
+			t.Errorf("%v.hasScope(%q) = %v, want %v", tt.granted, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestPermissionScopes(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	tests := []struct {
+		classic    bool
+		permission Permission
+		want       []string
+	}{
+		{permission: PermissionRepo, want: []string{"repo"}},
+		{permission: PermissionEmail, want: []string{"user:email"}},
+		{permission: PermissionProjectRead, want: []string{"read:project"}},
+		{permission: PermissionProjectWrite, want: []string{"project"}},
+		{classic: true, permission: PermissionProjectRead, want: []string{"repo", "read:org"}},
+		{classic: true, permission: PermissionProjectWrite, want: []string{"repo", "read:org"}},
+	}
+	for _, tt := range tests {
+		c := DefaultConfig()
+		c.ClassicProjects = tt.classic
+		if err := SetConfig(c); err != nil {
+			t.Fatal(err)
+		}
+		if got := tt.permission.scopes(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s.scopes() with classic projects %v = %v, want %v", tt.permission, tt.classic, got, tt.want)
+		}
+	}
+}
@@ -5,32 +5,31 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"regexp"
-	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/MatsuriJapon/git-matsuri/internal/git"
 	"github.com/google/go-github/v29/github"
 	"github.com/hashicorp/go-version"
-	"golang.org/x/oauth2"
 )
 
 const (
 	// TokenName is the environment variable name for the GitHub token.
 	TokenName = "MATSURI_TOKEN" // #nosec
-	owner     = "MatsuriJapon"
+	// toolOwner is the organization publishing git-matsuri itself, regardless of the configured owner.
+	toolOwner = "MatsuriJapon"
 )
 
 var (
-	ctx                 = context.Background()
-	projectCardListOpts = &github.ProjectCardListOptions{
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
-	}
-
-	projectRegex = regexp.MustCompile(`^Matsuri.*$`)
+	ctx = context.Background()
+	// Year selects the project of a festival year, even a closed one. When 0, the oldest open project is used.
+	Year int
+	// yearRegex finds the year in the name of a project.
+	yearRegex = regexp.MustCompile(`(?:^|\D)((?:19|20)\d{2})(?:\D|$)`)
+	// issueNumberRegex finds the number in the API URL of an issue or pull request.
+	issueNumberRegex = regexp.MustCompile(`/issues/(\d+)`)
 )
 
 type IssueGetterFunc func() (issues []*github.Issue, err error)
@@ -39,7 +38,7 @@ type IssueGetterFunc func() (issues []*github.Issue, err error)
 func GetLatestVersion() (v *version.Version, err error) {
 	client := GetClient()
 
-	release, _, err := client.Repositories.GetLatestRelease(ctx, owner, "git-matsuri")
+	release, _, err := client.Repositories.GetLatestRelease(ctx, toolOwner, "git-matsuri")
 	if err != nil {
 		return
 	}
@@ -53,14 +52,14 @@ func GetLatestVersion() (v *version.Version, err error) {
 	return
 }
 
-// GetMatsuriEmail gets the festivaljapon.com email of the current user, if available.
+// GetMatsuriEmail gets the organization email of the current user, if available.
 func GetMatsuriEmail() (email string, err error) {
 	client := GetClient()
 	userEmails, _, err := client.Users.ListEmails(ctx, nil)
 	if err != nil {
 		return
 	}
-	r := regexp.MustCompile(`^[\w._+-]+@festivaljapon.com$`)
+	r := GetConfig().emailRegex
 	for _, userEmail := range userEmails {
 		matches := r.FindStringSubmatch(userEmail.GetEmail())
 		if len(matches) != 1 {
@@ -74,22 +73,25 @@ func GetMatsuriEmail() (email string, err error) {
 
 // GetRepoName gets the repository name from the current directory.
 func GetRepoName() (repo string, err error) {
-	cmd := exec.Command("git", "config", "--get", "remote.origin.url")
-	url, err := cmd.Output()
+	url, err := git.RemoteURL("", "origin")
 	if err != nil {
 		return
 	}
-	r := regexp.MustCompile(`.+github\.com[:\/]MatsuriJapon\/(?P<repo>.+)\.git`)
-	match := r.FindStringSubmatch(string(url))
+	r := regexp.MustCompile(`.+github\.com[:\/]` + regexp.QuoteMeta(owner()) + `\/(?P<repo>.+)\.git`)
+	match := r.FindStringSubmatch(url)
+	if match == nil {
+		err = fmt.Errorf("the origin remote is not a %s repository", owner())
+		return
+	}
 	repo = match[1]
 	return
 }
 
-// GetRepoURL verifies that the given repository name matches a MatsuriJapon repository and returns its url.
+// GetRepoURL verifies that the given repository name matches a repository of the organization and returns its url.
 func GetRepoURL(name string, http bool) (url string, err error) {
 	client := GetClient()
 
-	repo, _, err := client.Repositories.Get(ctx, owner, name)
+	repo, _, err := client.Repositories.Get(ctx, owner(), name)
 	if err != nil || repo == nil {
 		return
 	}
@@ -101,24 +103,8 @@ func GetRepoURL(name string, http bool) (url string, err error) {
 	return
 }
 
-// GetClient retrieves a client from a context with value.
-func GetClient() (client *github.Client) {
-	ctx := context.Background()
-	token := os.Getenv(TokenName)
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
-	client = github.NewClient(tc)
-	return
-}
-
-func isCardIssue(c *github.ProjectCard) bool {
-	repoName, err := GetRepoName()
-	if err != nil {
-		return false
-	}
-	base := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/\\d+", owner, repoName)
-	re := regexp.MustCompile(base)
-	return re.MatchString(c.GetContentURL())
+func isCardIssue(c *Card, repoName string) bool {
+	return c.Number != 0 && strings.EqualFold(c.Repo, repoName)
 }
 
 // IsValidIssue verifies that an open Issue with the given number exists.
@@ -128,13 +114,24 @@ func IsValidIssue(num int) bool {
 		return false
 	}
 	client := GetClient()
-	issue, _, err := client.Issues.Get(ctx, owner, repoName, num)
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, num)
 	if err != nil {
 		return false
 	}
 	return issue.GetState() == "open" && !issue.IsPullRequest()
 }
 
+// GetIssue gets an Issue of the current repository.
+func GetIssue(num int) (issue *github.Issue, err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	issue, _, err = client.Issues.Get(ctx, owner(), repoName, num)
+	return
+}
+
 // IsExistingIssue verifies that the Issue exists and is not a pull request.
 func IsExistingIssue(num int) bool {
 	repoName, err := GetRepoName()
@@ -142,7 +139,7 @@ func IsExistingIssue(num int) bool {
 		return false
 	}
 	client := GetClient()
-	issue, _, err := client.Issues.Get(ctx, owner, repoName, num)
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, num)
 	if err != nil {
 		return false
 	}
@@ -156,7 +153,7 @@ func GetDefaultBranch() (branch *string, err error) {
 		return
 	}
 	client := GetClient()
-	repo, _, err := client.Repositories.Get(ctx, owner, repoName)
+	repo, _, err := client.Repositories.Get(ctx, owner(), repoName)
 	if err != nil {
 		return
 	}
@@ -164,9 +161,57 @@ func GetDefaultBranch() (branch *string, err error) {
 	return
 }
 
-func getProjectCards(columnName string) (cards []*github.ProjectCard, err error) {
-	client := GetClient()
+// baseBranch caches GetBaseBranch, which asks origin with git ls-remote, until the settings are replaced for the next command.
+var (
+	baseBranchMu sync.Mutex
+	baseBranch   string
+)
+
+func forgetBaseBranch() {
+	baseBranchMu.Lock()
+	defer baseBranchMu.Unlock()
+	baseBranch = ""
+}
+
+// GetBaseBranch gets the branch topic branches start from and are merged into: the branch of the project year,
+// such as v2020, when the repository has one, and the default branch otherwise.
+// It is looked up once per command.
+func GetBaseBranch() (branch *string, err error) {
+	baseBranchMu.Lock()
+	defer baseBranchMu.Unlock()
+	if baseBranch == "" {
+		if branch, err = lookUpBaseBranch(); err != nil {
+			return
+		}
+		if branch == nil {
+			return nil, errors.New("the repository has no default branch")
+		}
+		baseBranch = *branch
+	}
+	name := baseBranch
+	return &name, nil
+}
+
+func lookUpBaseBranch() (branch *string, err error) {
+	if format := GetConfig().YearBranchFormat; format != "" {
+		year := Year
+		if year == 0 {
+			if project, projectErr := GetProject(); projectErr == nil {
+				year, _ = ProjectYear(project)
+			}
+		}
+		if year != 0 {
+			name := fmt.Sprintf(format, year)
+			// ls-remote fails when the branch does not exist, in which case the repository does not use year branches
+			if _, lsErr := git.Run("", "ls-remote", "--exit-code", "--heads", "origin", name); lsErr == nil {
+				return &name, nil
+			}
+		}
+	}
+	return GetDefaultBranch()
+}
 
+func getProjectCards(columnName string) (cards []*Card, err error) {
 	project, err := GetProject()
 	if err != nil {
 		return
@@ -175,27 +220,53 @@ func getProjectCards(columnName string) (cards []*github.ProjectCard, err error)
 	if err != nil {
 		return
 	}
-	cards, _, err = client.Projects.ListProjectCards(ctx, column.GetID(), projectCardListOpts)
-	return
+	return getBoard().cards(column, 0)
 }
 
-func filterOutPRFromIssues(cards []*github.ProjectCard) (issues []*github.Issue, err error) {
-	repoName, err := GetRepoName()
-	if err != nil {
-		return
+// filterOutPRFromIssues resolves the issues of the cards of the given repository, the current one when empty, skipping pull requests,
+// and keeps at most limit issues when limit is positive. Issues that could not be fetched are left out and reported together in err.
+func filterOutPRFromIssues(cards []*Card, repoName string, limit int) (issues []*github.Issue, err error) {
+	if repoName == "" {
+		if repoName, err = GetRepoName(); err != nil {
+			// outside of a repository, no card belongs to the current one
+			return nil, nil
+		}
+	}
+	var issueCards []*Card
+	for _, card := range cards {
+		if isCardIssue(card, repoName) {
+			issueCards = append(issueCards, card)
+		}
 	}
-	client := GetClient()
 
-	for i := 0; i < len(cards); i++ {
-		if card := cards[i]; isCardIssue(card) {
-			num := GetIssueNumberFromCard(card)
-			issue, _, _ := client.Issues.Get(ctx, owner, repoName, num)
-			if issue.IsPullRequest() {
+	// with a limit, resolve the cards in batches so that we stop once enough issues were found
+	batch := len(issueCards)
+	if limit > 0 && limit < batch {
+		batch = limit
+	}
+	var fetchErrs FetchErrors
+	for start := 0; start < len(issueCards) && (limit <= 0 || len(issues) < limit); start += batch {
+		end := start + batch
+		if end > len(issueCards) {
+			end = len(issueCards)
+		}
+		fetched, fetchErr := fetchCardIssues(issueCards[start:end])
+		if errs, ok := fetchErr.(FetchErrors); ok {
+			fetchErrs = append(fetchErrs, errs...)
+		}
+		for _, issue := range fetched {
+			if issue == nil || issue.IsPullRequest() {
 				continue
 			}
 			issues = append(issues, issue)
 		}
 	}
+	if limit > 0 && len(issues) > limit {
+		issues = issues[:limit]
+	}
+	if len(fetchErrs) != 0 {
+		err = fetchErrs
+	}
 	return
 }
 
@@ -212,116 +283,148 @@ func GetIssueNumbersStartingWith(issues []*github.Issue, toComplete string) (iss
 
 // GetOpenIssuesForProject retrieves Issues for a Project.
 func GetOpenIssuesForProject() (issues []*github.Issue, err error) {
-	cards, err := getProjectCards("To do")
+	return getOpenIssuesForProject("", 0)
+}
+
+func getOpenIssuesForProject(repoName string, limit int) (issues []*github.Issue, err error) {
+	cards, err := getProjectCards(GetConfig().Columns.Todo)
 	if err != nil {
 		return
 	}
 
-	return filterOutPRFromIssues(cards)
+	return filterOutPRFromIssues(cards, repoName, limit)
 }
 
 // GetOpenIssuesForProject retrieves in-progress Issues for a Project.
 func GetInProgressIssues() (issues []*github.Issue, err error) {
-	cards, err := getProjectCards("In progress")
+	cards, err := getProjectCards(GetConfig().Columns.InProgress)
 	if err != nil {
 		return
 	}
 
-	return filterOutPRFromIssues(cards)
+	return filterOutPRFromIssues(cards, "", 0)
 }
 
-// GetIssueNumberFromCard gets the Issue number from a Card.
+// GetIssueNumberFromCard gets the Issue number from a Card, or 0 if its content is not an issue or pull request.
 func GetIssueNumberFromCard(c *github.ProjectCard) (id int) {
-	r := regexp.MustCompile(`(?:issues/)(?P<id>\d+)`)
-	matches := r.FindStringSubmatch(c.GetContentURL())
+	matches := issueNumberRegex.FindStringSubmatch(c.GetContentURL())
+	if matches == nil {
+		return
+	}
 	id, _ = strconv.Atoi(matches[1])
 	return
 }
 
-// GetRepoNameFromURL gets the Repository name from a URL.
+// GetRepoNameFromURL gets the Repository name from a URL, or "" if it is not the URL of a repository of the organization.
 func GetRepoNameFromURL(url string) (repoName string) {
-	r := regexp.MustCompile(`(?:MatsuriJapon/)(?P<repoName>[^/]+)`)
+	// GitHub compares logins without regard to case, and may spell the owner differently in its URLs
+	r := regexp.MustCompile(`(?i)/` + regexp.QuoteMeta(owner()) + `/([^/]+)`)
 	matches := r.FindStringSubmatch(url)
+	if matches == nil {
+		return
+	}
 	repoName = matches[1]
 	return
 }
 
-// GetProject retrieves the oldest matching open project.
-func GetProject() (project *github.Project, err error) {
-	client := GetClient()
-
-	projects, _, err := client.Organizations.ListProjects(ctx, owner, nil)
+// GetProject retrieves the oldest matching open project, or the oldest matching project of Year when set.
+func GetProject() (project *Project, err error) {
+	projects, err := getBoard().projects()
 	if err != nil {
 		return
 	}
-	sort.Slice(projects, func(i, j int) bool {
-		return projects[i].GetID() < projects[j].GetID()
-	})
 	for i := 0; i < len(projects); i++ {
-		if projectRegex.MatchString(projects[i].GetName()) {
+		if !GetConfig().projectRegex.MatchString(projects[i].Name) {
+			continue
+		}
+		if Year == 0 && !projects[i].Closed {
+			project = projects[i]
+			return
+		}
+		if year, ok := ProjectYear(projects[i]); Year != 0 && ok && year == Year {
 			project = projects[i]
 			return
 		}
 	}
+	if Year != 0 {
+		err = fmt.Errorf("a project for %d was not found", Year)
+		return
+	}
 	err = fmt.Errorf("Error: a suitable project was not found")
 	return
 }
 
-// GetProjectColumnByName gets the column by its name.
-func GetProjectColumnByName(project *github.Project, columnName string) (column *github.ProjectColumn, err error) {
-	client := GetClient()
+// ProjectYear gets the festival year in the name of a project.
+func ProjectYear(project *Project) (year int, ok bool) {
+	match := yearRegex.FindStringSubmatch(project.Name)
+	if match == nil {
+		return
+	}
+	year, err := strconv.Atoi(match[1])
+	return year, err == nil
+}
 
-	columns, _, _ := client.Projects.ListProjectColumns(ctx, project.GetID(), nil)
+// GetProjectColumnByName gets the column by its name.
+func GetProjectColumnByName(project *Project, columnName string) (column *Column, err error) {
+	columns, err := getBoard().columns(project)
+	if err != nil {
+		return
+	}
 	for i := 0; i < len(columns); i++ {
-		if columns[i].GetName() == columnName {
+		if columns[i].Name == columnName {
 			column = columns[i]
 			return
 		}
 	}
-	err = fmt.Errorf("Error: there is no %s column for %s", columnName, project.GetName())
+	err = fmt.Errorf("Error: there is no %s column for %s", columnName, project.Name)
 	return
 }
 
 // GetProjectCardInColumn gets the project card associated with the given issue number.
-func GetProjectCardInColumn(column *github.ProjectColumn, issueNumber int) *github.ProjectCard {
+func GetProjectCardInColumn(column *Column, issueNumber int) *Card {
 	repoName, err := GetRepoName()
 	if err != nil {
 		return nil
 	}
-	client := GetClient()
-	cards, _, _ := client.Projects.ListProjectCards(ctx, column.GetID(), projectCardListOpts)
-	for i := 0; i < len(cards); i++ {
-		if card := cards[i]; isCardIssue(card) {
-			num := GetIssueNumberFromCard(card)
-			_, _, err := client.Issues.Get(ctx, owner, repoName, num)
-			if err != nil {
-				continue
-			}
-			if num == issueNumber {
-				return card
-			}
-		}
+	card, err := getBoard().findCard(column, repoName, issueNumber)
+	if err != nil {
+		return nil
 	}
-	return nil
+	return card
 }
 
 // GetRepoIssues gets the issues for the current repository, regardless if they belong to a project or not.
-func GetRepoIssues() (issues []*github.Issue, err error) {
+// At most limit issues are returned when limit is positive, not counting the pull requests GitHub lists along with them.
+func GetRepoIssues(limit int) (issues []*github.Issue, err error) {
 	repoName, err := GetRepoName()
 	if err != nil {
 		return
 	}
 	client := GetClient()
-	issues, _, err = client.Issues.ListByRepo(ctx, owner, repoName, nil)
+	p := newPager(func(opts github.ListOptions) ([]*github.Issue, *github.Response, error) {
+		return client.Issues.ListByRepo(ctx, owner(), repoName, &github.IssueListByRepoOptions{ListOptions: opts})
+	})
+	for limit <= 0 || len(issues) < limit {
+		issue, ok := p.Next()
+		if !ok {
+			break
+		}
+		if issue.IsPullRequest() {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+	err = p.Err()
 	return
 }
 
-// GetIssues gets issues that need to be worked on.
-func GetOpenIssues(repoOnly bool) ([]*github.Issue, error) {
+// GetIssues gets issues that need to be worked on, at most limit of them when limit is positive.
+// The project issues are those of repoName, or of the current repository when it is empty.
+func GetOpenIssues(repoOnly bool, repoName string, limit int) ([]*github.Issue, error) {
 	if repoOnly {
-		return GetRepoIssues()
+		return GetRepoIssues(limit)
 	}
-	return GetOpenIssuesForProject()
+	return getOpenIssuesForProject(repoName, limit)
 }
 
 func createPR(newPr *github.NewPullRequest) (pr *github.PullRequest, err error) {
@@ -331,7 +434,7 @@ func createPR(newPr *github.NewPullRequest) (pr *github.PullRequest, err error)
 	}
 	client := GetClient()
 
-	pr, _, err = client.PullRequests.Create(ctx, owner, repoName, newPr)
+	pr, _, err = client.PullRequests.Create(ctx, owner(), repoName, newPr)
 	if err != nil {
 		return
 	}
@@ -339,32 +442,119 @@ func createPR(newPr *github.NewPullRequest) (pr *github.PullRequest, err error)
 	if err != nil {
 		return
 	}
-	todo, err := GetProjectColumnByName(project, "To do")
+	todo, err := GetProjectColumnByName(project, GetConfig().Columns.Todo)
+	if err != nil {
+		return
+	}
+	err = getBoard().addPullRequest(todo, pr)
+	return
+}
+
+// ensurePullRequestCard adds a pull request to the to do column of the project, unless it is already on the board.
+func ensurePullRequestCard(pr *github.PullRequest) (err error) {
+	repoName, err := GetRepoName()
 	if err != nil {
 		return
 	}
-	cardOpt := &github.ProjectCardOptions{
-		ContentID:   pr.GetID(),
-		ContentType: "PullRequest",
+	project, err := GetProject()
+	if err != nil {
+		return
 	}
-	_, _, err = client.Projects.CreateProjectCard(ctx, todo.GetID(), cardOpt)
+	b := getBoard()
+	card, _, err := b.locateCard(project, repoName, pr.GetNumber())
+	if err != nil || card != nil {
+		return
+	}
+	todo, err := GetProjectColumnByName(project, GetConfig().Columns.Todo)
+	if err != nil {
+		return
+	}
+	return b.addPullRequest(todo, pr)
+}
+
+// OpenedPR is the pull request opened or updated by CreatePRForIssueNumber and CreateFixPRForIssueNumber.
+type OpenedPR struct {
+	*github.PullRequest
+	// Existing is set when an open PR was updated rather than a new one created.
+	Existing bool
+	// WantedBase is the branch new PRs are opened against, which an existing PR may not use.
+	WantedBase string
+}
+
+// isFixPR tells the fix PRs of an issue, opened by CreateFixPRForIssueNumber, apart from its other PRs.
+// GitHub does not record the kind of a PR, so this is a guess from the title and body that CreateFixPRForIssueNumber
+// writes, which fails if someone edited them.
+func isFixPR(pr *github.PullRequest, issueNum int) bool {
+	return strings.HasPrefix(pr.GetTitle(), BranchName(issueNum)+"-fix") || strings.Contains(pr.GetBody(), fmt.Sprintf("Fixes PR for #%d", issueNum))
+}
+
+// openPR creates a PR, or updates the open one of the same kind from the same head so that opening it again is harmless.
+// With recreate, an open PR to another base is closed and replaced by a new one.
+func openPR(newPr *github.NewPullRequest, issueNum int, fix, recreate bool) (opened *OpenedPR, err error) {
+	pulls, err := GetPullRequestsForBranch(newPr.GetHead())
+	if err != nil {
+		return
+	}
+	var pr *github.PullRequest
+	for _, open := range pulls {
+		if open.GetState() == "open" {
+			pr = open
+			break
+		}
+	}
+	if pr == nil {
+		return createOpenedPR(newPr)
+	}
+	if isFixPR(pr, issueNum) != fix {
+		kind := "a fix PR"
+		if fix {
+			kind = "not a fix PR"
+		}
+		return nil, fmt.Errorf("#%d is already open from %s and is %s: %q. Close it first, or push to another branch", pr.GetNumber(), newPr.GetHead(), kind, pr.GetTitle())
+	}
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	if recreate && pr.GetBase().GetRef() != newPr.GetBase() {
+		if _, _, err = client.PullRequests.Edit(ctx, owner(), repoName, pr.GetNumber(), &github.PullRequest{State: github.String("closed")}); err != nil {
+			return
+		}
+		return createOpenedPR(newPr)
+	}
+	pr, _, err = client.PullRequests.Edit(ctx, owner(), repoName, pr.GetNumber(), &github.PullRequest{Title: newPr.Title, Body: newPr.Body})
+	if err != nil {
+		return
+	}
+	opened = &OpenedPR{PullRequest: pr, Existing: true, WantedBase: newPr.GetBase()}
+	err = ensurePullRequestCard(pr)
 	return
 }
 
-// CreatePRForIssueNumber creates a new PR for the given issue and returns the created card.
-func CreatePRForIssueNumber(issueNum int, noclose bool) (pr *github.PullRequest, err error) {
+// createOpenedPR creates a PR. The PR is returned even if it could not be placed on the project.
+func createOpenedPR(newPr *github.NewPullRequest) (opened *OpenedPR, err error) {
+	pr, err := createPR(newPr)
+	if pr != nil {
+		opened = &OpenedPR{PullRequest: pr, WantedBase: newPr.GetBase()}
+	}
+	return
+}
+
+// CreatePRForIssueNumber creates a new PR for the given issue, or updates the open one from head.
+// With recreate, an open PR to another base branch is closed and a new one is created.
+func CreatePRForIssueNumber(issueNum int, head string, noclose, recreate bool) (opened *OpenedPR, err error) {
 	repoName, err := GetRepoName()
 	if err != nil {
 		return
 	}
 	client := GetClient()
-	issue, _, err := client.Issues.Get(ctx, owner, repoName, issueNum)
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, issueNum)
 	if err != nil {
 		return
 	}
-	title := fmt.Sprintf("ISSUE-%d: %s", issue.GetNumber(), issue.GetTitle())
-	head := fmt.Sprintf("ISSUE-%d", issue.GetNumber())
-	base, err := GetDefaultBranch()
+	title := fmt.Sprintf("%s: %s", BranchName(issue.GetNumber()), issue.GetTitle())
+	base, err := GetBaseBranch()
 	if err != nil {
 		return
 	}
@@ -378,23 +568,22 @@ func CreatePRForIssueNumber(issueNum int, noclose bool) (pr *github.PullRequest,
 		Base:  base,
 		Body:  github.String(body),
 	}
-	return createPR(newPr)
+	return openPR(newPr, issueNum, false, recreate)
 }
 
-// CreateFixPRForIssueNumber creates a fix PR for the provided issue.
-func CreateFixPRForIssueNumber(issueNum int, noclose bool) (pr *github.PullRequest, err error) {
+// CreateFixPRForIssueNumber creates a fix PR for the provided issue, or updates the open one from head. See CreatePRForIssueNumber.
+func CreateFixPRForIssueNumber(issueNum int, head string, noclose, recreate bool) (opened *OpenedPR, err error) {
 	repoName, err := GetRepoName()
 	if err != nil {
 		return
 	}
 	client := GetClient()
-	issue, _, err := client.Issues.Get(ctx, owner, repoName, issueNum)
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, issueNum)
 	if err != nil {
 		return
 	}
-	title := fmt.Sprintf("ISSUE-%d-fix: %s", issue.GetNumber(), issue.GetTitle())
-	head := fmt.Sprintf("ISSUE-%d", issue.GetNumber())
-	base, err := GetDefaultBranch()
+	title := fmt.Sprintf("%s-fix: %s", BranchName(issue.GetNumber()), issue.GetTitle())
+	base, err := GetBaseBranch()
 	if err != nil {
 		return
 	}
@@ -408,7 +597,7 @@ func CreateFixPRForIssueNumber(issueNum int, noclose bool) (pr *github.PullReque
 		Base:  base,
 		Body:  github.String(body),
 	}
-	return createPR(newPr)
+	return openPR(newPr, issueNum, true, recreate)
 }
 
 // MoveProjectCardForProject moves the Issue to the Doing project column.
@@ -417,11 +606,12 @@ func MoveProjectCardForProject(num int) (err error) {
 	if err != nil {
 		return
 	}
-	todo, err := GetProjectColumnByName(project, "To do")
+	columns := GetConfig().Columns
+	todo, err := GetProjectColumnByName(project, columns.Todo)
 	if err != nil {
 		return
 	}
-	doing, err := GetProjectColumnByName(project, "In progress")
+	doing, err := GetProjectColumnByName(project, columns.InProgress)
 	if err != nil {
 		return
 	}
@@ -430,16 +620,168 @@ func MoveProjectCardForProject(num int) (err error) {
 		// handle the case where the Issue has already been moved to Doing
 		card = GetProjectCardInColumn(doing, num)
 		if card == nil {
-			return fmt.Errorf("The specified Issue is not in %s's To do or Doing columns", project.GetName())
+			return fmt.Errorf("The specified Issue is not in %s's %s or %s columns", project.Name, columns.Todo, columns.InProgress)
 		}
 		return
 	}
-	opt := &github.ProjectCardMoveOptions{
-		Position: "top",
-		ColumnID: doing.GetID(),
+	return getBoard().moveCard(card, doing)
+}
+
+// MoveIssueCard moves the card of an issue of any repository of the organization from one column of the project to another.
+func MoveIssueCard(project *Project, repo string, issueNumber int, from, to string) (err error) {
+	fromColumn, err := GetProjectColumnByName(project, from)
+	if err != nil {
+		return
+	}
+	toColumn, err := GetProjectColumnByName(project, to)
+	if err != nil {
+		return
+	}
+	card, err := getBoard().findCard(fromColumn, repo, issueNumber)
+	if err != nil {
+		return
+	}
+	if card == nil {
+		return fmt.Errorf("%s#%d is not in the %s column anymore", repo, issueNumber, from)
+	}
+	return getBoard().moveCard(card, toColumn)
+}
+
+// MoveProjectCardBackToTodo moves the card of an Issue from the in progress column back to the to do one.
+func MoveProjectCardBackToTodo(num int) (err error) {
+	project, err := GetProject()
+	if err != nil {
+		return
+	}
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	columns := GetConfig().Columns
+	return MoveIssueCard(project, repoName, num, columns.InProgress, columns.Todo)
+}
+
+// MoveProjectCardToDone moves the card of an Issue to the done column, from whichever column it is in.
+func MoveProjectCardToDone(num int) (err error) {
+	project, err := GetProject()
+	if err != nil {
+		return
+	}
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	b := getBoard()
+	done, err := GetProjectColumnByName(project, GetConfig().Columns.Done)
+	if err != nil {
+		return
+	}
+	card, column, err := b.locateCard(project, repoName, num)
+	if err != nil {
+		return
+	}
+	if card == nil {
+		return fmt.Errorf("%s#%d is not in %s", repoName, num, project.Name)
+	}
+	if column == done.Name {
+		return
+	}
+	return b.moveCard(card, done)
+}
+
+// GetPullRequestsForBranch lists the pull requests opened from a branch of the current repository, whatever their state, newest first.
+func GetPullRequestsForBranch(branch string) (pulls []*github.PullRequest, err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	return newPager(func(opts github.ListOptions) ([]*github.PullRequest, *github.Response, error) {
+		return client.PullRequests.List(ctx, owner(), repoName, &github.PullRequestListOptions{
+			State:       "all",
+			Head:        owner() + ":" + branch,
+			ListOptions: opts,
+		})
+	}).All(0)
+}
+
+// GetMergedPullRequest gets the merged pull request opened from a branch, or explains why there is none.
+func GetMergedPullRequest(branch string) (pr *github.PullRequest, err error) {
+	pulls, err := GetPullRequestsForBranch(branch)
+	if err != nil {
+		return
+	}
+	if len(pulls) == 0 {
+		return nil, fmt.Errorf("no pull request was opened from %s", branch)
+	}
+	for _, pr := range pulls {
+		if pr.MergedAt != nil {
+			return pr, nil
+		}
+	}
+	for _, pr := range pulls {
+		if pr.GetState() == "open" {
+			return nil, fmt.Errorf("the pull request #%d from %s is not merged yet", pr.GetNumber(), branch)
+		}
+	}
+	return nil, fmt.Errorf("the pull request #%d from %s was closed without being merged", pulls[0].GetNumber(), branch)
+}
+
+// GetIssueAssignees gets the logins of the users assigned to an Issue.
+func GetIssueAssignees(issueNum int) (logins []string, err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, issueNum)
+	if err != nil {
+		return
+	}
+	for _, user := range issue.Assignees {
+		logins = append(logins, user.GetLogin())
+	}
+	return
+}
+
+// AssignIssue assigns an Issue to the given user, and adds the in progress label of the repository, if any.
+func AssignIssue(issueNum int, login string) (err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
 	}
 	client := GetClient()
-	_, err = client.Projects.MoveProjectCard(ctx, card.GetID(), opt)
+	if _, _, err = client.Issues.AddAssignees(ctx, owner(), repoName, issueNum, []string{login}); err != nil {
+		return
+	}
+	if label := GetConfig().InProgressLabel; label != "" {
+		_, _, err = client.Issues.AddLabelsToIssue(ctx, owner(), repoName, issueNum, []string{label})
+	}
+	return
+}
+
+// UnassignIssue removes the given user from the assignees of an Issue, along with the in progress label of the repository, if any.
+func UnassignIssue(issueNum int, login string) (err error) {
+	repoName, err := GetRepoName()
+	if err != nil {
+		return
+	}
+	client := GetClient()
+	issue, _, err := client.Issues.RemoveAssignees(ctx, owner(), repoName, issueNum, []string{login})
+	if err != nil {
+		return
+	}
+	label := GetConfig().InProgressLabel
+	if label == "" {
+		return
+	}
+	// removing a label the issue does not have fails
+	for _, l := range issue.Labels {
+		if strings.EqualFold(l.GetName(), label) {
+			_, err = client.Issues.RemoveLabelForIssue(ctx, owner(), repoName, issueNum, l.GetName())
+			return
+		}
+	}
 	return
 }
 
@@ -451,7 +793,7 @@ func ReopenIssue(issueNum int) (err error) {
 	}
 	client := GetClient()
 
-	issue, _, err := client.Issues.Get(ctx, owner, repoName, issueNum)
+	issue, _, err := client.Issues.Get(ctx, owner(), repoName, issueNum)
 	if err != nil {
 		return
 	}
@@ -462,38 +804,87 @@ func ReopenIssue(issueNum int) (err error) {
 	reopenRequest := &github.IssueRequest{
 		State: github.String("open"),
 	}
-	_, _, err = client.Issues.Edit(ctx, owner, repoName, issueNum, reopenRequest)
+	_, _, err = client.Issues.Edit(ctx, owner(), repoName, issueNum, reopenRequest)
 	return
 }
 
-// PrintProjectKanban prints the project kanban.
-func PrintProjectKanban(project *github.Project) {
-	client := GetClient()
-	columns, _, _ := client.Projects.ListProjectColumns(ctx, project.GetID(), nil)
-	for i := 0; i < len(columns); i++ {
-		column := columns[i]
-		fmt.Println(column.GetName())
-		cards, _, _ := client.Projects.ListProjectCards(ctx, column.GetID(), projectCardListOpts)
-		for j := 0; j < len(cards); j++ {
-			card := cards[j]
-			num := GetIssueNumberFromCard(card)
-			repoName := GetRepoNameFromURL(card.GetContentURL())
-			issue, _, _ := client.Issues.Get(ctx, owner, repoName, num)
-			fmt.Printf("%d [%s]: %s\n", issue.GetNumber(), repoName, issue.GetTitle())
+// GetKanbanReport gets the issues of every column of the project, at most limit per column when limit is positive.
+// Issues that could not be fetched are left out and reported in err.
+func GetKanbanReport(project *Project, limit int) (report *KanbanReport, err error) {
+	b := getBoard()
+	columns, err := b.columns(project)
+	if err != nil {
+		return
+	}
+	var cards []*Card
+	columnSizes := make([]int, len(columns))
+	for i, column := range columns {
+		columnCards, err := b.cards(column, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, card := range columnCards {
+			// notes and draft issues have no content to show
+			if card.Number == 0 {
+				continue
+			}
+			cards = append(cards, card)
+			columnSizes[i]++
 		}
-		fmt.Println()
 	}
-}
+	issues, err := fetchCardIssues(cards)
 
-// PrintIssues prints issues.
-func PrintIssues(issues []*github.Issue) {
-	for i := 0; i < len(issues); i++ {
-		issue := issues[i]
-		// sanity check
-		if issue.IsPullRequest() {
-			continue
+	report = &KanbanReport{SchemaVersion: ReportSchemaVersion, Project: project.Name, Columns: []*KanbanColumn{}}
+	next := 0
+	for i, column := range columns {
+		reportColumn := &KanbanColumn{Name: column.Name, Issues: []*ReportIssue{}}
+		for j := next; j < next+columnSizes[i]; j++ {
+			if issues[j] == nil {
+				continue
+			}
+			reportColumn.Issues = append(reportColumn.Issues, NewReportIssue(issues[j], cards[j].Repo, column.Name))
 		}
-		repoName := GetRepoNameFromURL(issue.GetRepositoryURL())
-		fmt.Printf("%d [%s]: %s\n", issue.GetNumber(), repoName, issue.GetTitle())
+		next += columnSizes[i]
+		report.Columns = append(report.Columns, reportColumn)
+	}
+	return
+}
+
+// PrintProjectKanban prints the project kanban in the given format, showing at most limit cards per column when limit is positive.
+// The issues of all the cards are fetched concurrently; those that could not be fetched are reported at the end.
+func PrintProjectKanban(project *Project, limit int, format string) (err error) {
+	report, err := GetKanbanReport(project, limit)
+	if report == nil {
+		return
+	}
+	if writeErr := report.Write(os.Stdout, format); writeErr != nil {
+		return writeErr
+	}
+	return
+}
+
+// PrintIssues prints issues in the given format, along with the project column they were found in, if any.
+// The text and markdown formats group them by groupBy when it is not empty.
+func PrintIssues(issues []*github.Issue, column, format, groupBy string) error {
+	report := NewIssuesReport(issues, column)
+	report.GroupBy = groupBy
+	return report.Write(os.Stdout, format)
+}
+
+// GetRateLimits gets the remaining GitHub API quota for the current token.
+func GetRateLimits() (limits *github.RateLimits, err error) {
+	client := GetClient()
+	limits, _, err = client.RateLimits.RateLimits(ctx)
+	return
+}
+
+// GetAuthenticatedUser gets the login of the user owning the token.
+func GetAuthenticatedUser() (login string, err error) {
+	client := GetClient()
+	user, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return
 	}
+	login = user.GetLogin()
+	return
 }
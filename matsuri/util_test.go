@@ -0,0 +1,86 @@
+package matsuri
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+)
+
+func TestGetRepoNameFromURL(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	if err := SetConfig(DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.github.com/repos/MatsuriJapon/web", want: "web"},
+		{url: "https://api.github.com/repos/MatsuriJapon/web/issues/12", want: "web"},
+		{url: "https://api.github.com/repos/matsurijapon/web/issues/12", want: "web"},
+		{url: "https://github.example.com/api/v3/repos/MATSURIJAPON/api", want: "api"},
+		{url: "https://api.github.com/repos/someone/web/issues/12"},
+		{url: "https://api.github.com/repos/MatsuriJaponX/web"},
+		{url: ""},
+	}
+	for _, tt := range tests {
+		if got := GetRepoNameFromURL(tt.url); got != tt.want {
+			t.Errorf("GetRepoNameFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNewClassicCard(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	if err := SetConfig(DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		contentURL string
+		wantRepo   string
+		wantNumber int
+	}{
+		{contentURL: "https://api.github.com/repos/MatsuriJapon/web/issues/12", wantRepo: "web", wantNumber: 12},
+		{contentURL: "https://api.github.com/repos/matsurijapon/web/issues/12", wantRepo: "web", wantNumber: 12},
+		// notes, and content that cannot be resolved, are not issues
+		{contentURL: ""},
+		{contentURL: "https://api.github.com/repos/someone/web/issues/12"},
+		{contentURL: "https://api.github.com/repos/MatsuriJapon/web"},
+	}
+	for _, tt := range tests {
+		c := &github.ProjectCard{ContentURL: github.String(tt.contentURL)}
+		if tt.contentURL == "" {
+			c.ContentURL = nil
+		}
+		card := newClassicCard(c)
+		if card.Repo != tt.wantRepo || card.Number != tt.wantNumber {
+			t.Errorf("newClassicCard(%q) is %s#%d, want %s#%d", tt.contentURL, card.Repo, card.Number, tt.wantRepo, tt.wantNumber)
+		}
+		if number := GetIssueNumberFromCard(c); tt.wantNumber != 0 && number != tt.wantNumber {
+			t.Errorf("GetIssueNumberFromCard(%q) = %d, want %d", tt.contentURL, number, tt.wantNumber)
+		}
+	}
+}
+
+func TestIsFixPR(t *testing.T) {
+	defer SetConfig(DefaultConfig())
+	if err := SetConfig(DefaultConfig()); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		title, body string
+		want        bool
+	}{
+		{title: "ISSUE-12-fix: Fix the header", body: "Fixes PR for #12\nCloses #12\n", want: true},
+		{title: "Header fixes", body: "Fixes PR for #12\n", want: true},
+		{title: "ISSUE-12-fix: Fix the header", body: "", want: true},
+		{title: "ISSUE-12: Fix the header", body: "Closes #12\n"},
+		{title: "ISSUE-1-fix: Fix the footer", body: "Fixes PR for #1\n"},
+	}
+	for _, tt := range tests {
+		pr := &github.PullRequest{Title: github.String(tt.title), Body: github.String(tt.body)}
+		if got := isFixPR(pr, 12); got != tt.want {
+			t.Errorf("isFixPR(%q, %q) = %v, want %v", tt.title, tt.body, got, tt.want)
+		}
+	}
+}
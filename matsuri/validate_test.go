@@ -0,0 +1,60 @@
+package matsuri_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MatsuriJapon/git-matsuri/matsuri"
+	"github.com/MatsuriJapon/git-matsuri/matsuri/fake"
+)
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		name        string
+		classic     bool
+		scopes      []string
+		permissions []matsuri.Permission
+		// wantMissing lists the scopes named in the error, nil when the token is valid
+		wantMissing []string
+	}{
+		{name: "no permission needed", scopes: []string{}},
+		{name: "all scopes", scopes: []string{"repo", "project", "user:email"}, permissions: []matsuri.Permission{matsuri.PermissionRepo, matsuri.PermissionProjectWrite, matsuri.PermissionEmail}},
+		{name: "implied scopes", scopes: []string{"repo", "project", "user"}, permissions: []matsuri.Permission{matsuri.PermissionProjectRead, matsuri.PermissionEmail}},
+		{name: "missing project scope", scopes: []string{"repo"}, permissions: []matsuri.Permission{matsuri.PermissionRepo, matsuri.PermissionProjectWrite}, wantMissing: []string{"project"}},
+		{name: "read scope is not enough to write", scopes: []string{"repo", "read:project"}, permissions: []matsuri.Permission{matsuri.PermissionProjectWrite}, wantMissing: []string{"project"}},
+		{name: "classic projects", classic: true, scopes: []string{"repo"}, permissions: []matsuri.Permission{matsuri.PermissionProjectWrite}, wantMissing: []string{"read:org"}},
+		{name: "several missing scopes", scopes: []string{}, permissions: []matsuri.Permission{matsuri.PermissionRepo, matsuri.PermissionEmail}, wantMissing: []string{"repo", "user:email"}},
+		{name: "fine-grained token", permissions: []matsuri.Permission{matsuri.PermissionEmail, matsuri.PermissionProjectRead}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the result of token checks is cached in the user cache directory
+			cache := t.TempDir()
+			t.Setenv("XDG_CACHE_HOME", cache)
+			t.Setenv("HOME", cache)
+			t.Setenv(matsuri.TokenName, "test-token")
+			c := matsuri.DefaultConfig()
+			c.ClassicProjects = tt.classic
+			if err := matsuri.SetConfig(c); err != nil {
+				t.Fatal(err)
+			}
+			g := fake.New()
+			g.Scopes = tt.scopes
+			matsuri.SetBackend(g.Backend())
+
+			err := matsuri.ValidateToken("git matsuri test", tt.permissions)
+			if tt.wantMissing == nil {
+				if err != nil {
+					t.Errorf("ValidateToken() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("ValidateToken() accepted the token")
+			}
+			if !strings.Contains(err.Error(), ": "+strings.Join(tt.wantMissing, ", ")+"\n") {
+				t.Errorf("ValidateToken() error = %q, want the missing scopes %v", err, tt.wantMissing)
+			}
+		})
+	}
+}